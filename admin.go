@@ -0,0 +1,264 @@
+package ugulru
+
+import (
+	"cmp"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// registry holds the caches registered with Register, by name.
+var registry = struct {
+	sync.RWMutex
+	caches map[string]adminCache
+}{caches: make(map[string]adminCache)}
+
+// adminCache is the type-erased view of a registered cache used by the admin handler, with keys in their encoded form.
+type adminCache interface {
+	stats() Stats
+	top(n int) []adminKey
+	lookup(key string) (value any, expires time.Time, ok bool, err error)
+	remove(key string) error
+	clear() int
+}
+
+// Register registers the given cache under the given name, so it can be inspected and managed with the handler
+// returned by NewAdminHandler. Keys are shown and parsed in the form given by the key codec. Registering a cache under
+// a name that is already registered replaces it.
+func Register[K comparable, V any](name string, cache *InMemoryCache[K, V], keys KeyCodec[K]) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.caches[name] = &registered[K, V]{cache: cache, keys: keys}
+}
+
+// Unregister removes the cache registered under the given name, if any.
+func Unregister(name string) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	delete(registry.caches, name)
+}
+
+// registered is a cache registered with Register.
+type registered[K comparable, V any] struct {
+	cache *InMemoryCache[K, V]
+	keys  KeyCodec[K]
+}
+
+func (r *registered[K, V]) stats() Stats {
+	return r.cache.Stats()
+}
+
+func (r *registered[K, V]) top(n int) []adminKey {
+	var keys []adminKey
+	for _, hot := range r.cache.TopN(n) {
+		if encoded, err := r.keys.EncodeKey(hot.Key); err == nil {
+			keys = append(keys, adminKey{Key: encoded, Frequency: hot.Frequency})
+		}
+	}
+	return keys
+}
+
+func (r *registered[K, V]) lookup(key string) (any, time.Time, bool, error) {
+	k, err := r.keys.DecodeKey(key)
+	if err != nil {
+		return nil, time.Time{}, false, err
+	}
+	value, expires, ok := r.cache.peek(k)
+	return value, expires, ok, nil
+}
+
+func (r *registered[K, V]) remove(key string) error {
+	k, err := r.keys.DecodeKey(key)
+	if err != nil {
+		return err
+	}
+	r.cache.discard(k)
+	return nil
+}
+
+func (r *registered[K, V]) clear() int {
+	return r.cache.discardAll()
+}
+
+// peek returns the value of the live entry with the given key and the time it expires, without counting a hit or a
+// miss and without affecting the recency of the entry.
+func (c *InMemoryCache[K, V]) peek(key K) (V, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.cache[key]
+	if !ok || c.expired(entry) || entry.err != nil {
+		var zero V
+		return zero, time.Time{}, false
+	}
+	return entry.value, c.timeOf(c.expiresAt(entry)), true
+}
+
+// AdminConfig configures the handler returned by NewAdminHandler.
+type AdminConfig struct {
+	// Authorize, if set, is called for every request, which is rejected with 403 Forbidden if it returns false.
+	Authorize func(r *http.Request) bool
+	// AllowWrites enables deleting keys and clearing caches. Without it, the handler is read-only.
+	AllowWrites bool
+	// ShowValues enables returning the values of the looked up keys, encoded as JSON. Without it, lookups only report
+	// whether the key exists and when it expires, so cached personal data is not exposed.
+	ShowValues bool
+}
+
+// adminSummary is the summary of a registered cache.
+type adminSummary struct {
+	Name      string  `json:"name"`
+	Len       int     `json:"len"`
+	Capacity  int     `json:"capacity"`
+	Hits      uint64  `json:"hits"`
+	Misses    uint64  `json:"misses"`
+	Evictions uint64  `json:"evictions"`
+	HitRatio  float64 `json:"hit_ratio"`
+}
+
+// adminDetails is the summary of a registered cache together with its hottest keys.
+type adminDetails struct {
+	adminSummary
+	TopKeys []adminKey `json:"top_keys"`
+}
+
+// adminKey is a hot key of a registered cache.
+type adminKey struct {
+	Key       string  `json:"key"`
+	Frequency float64 `json:"frequency"`
+}
+
+// adminEntry is the result of a key lookup.
+type adminEntry struct {
+	Key     string     `json:"key"`
+	Found   bool       `json:"found"`
+	Expires *time.Time `json:"expires,omitempty"`
+	Value   any        `json:"value,omitempty"`
+}
+
+// NewAdminHandler returns an http.Handler for on-call debugging of the caches registered with Register, to be mounted
+// with http.StripPrefix, e.g. at /debug/ugulru/. It serves JSON on the following routes:
+//
+//	GET    /                   lists the registered caches with their size and hit ratio
+//	GET    /{name}?top=n       shows a cache with its n hottest keys, 10 by default
+//	GET    /{name}/keys/{key}  looks a key up without affecting its recency or the statistics
+//	DELETE /{name}/keys/{key}  removes a key, if AllowWrites is set
+//	POST   /{name}/clear       removes all the entries, if AllowWrites is set and the confirm query parameter repeats
+//	                           the name of the cache
+//
+// Keys are only removed from the caches, not from the stores set by WithWriteThrough or WithWriteBehind.
+func NewAdminHandler(config AdminConfig) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		registry.RLock()
+		summaries := make([]adminSummary, 0, len(registry.caches))
+		for name, cache := range registry.caches {
+			summaries = append(summaries, summarize(name, cache))
+		}
+		registry.RUnlock()
+
+		slices.SortFunc(summaries, func(a, b adminSummary) int {
+			return cmp.Compare(a.Name, b.Name)
+		})
+		writeJSON(w, http.StatusOK, summaries)
+	})
+	mux.HandleFunc("GET /{name}", withCache(func(w http.ResponseWriter, r *http.Request, name string, cache adminCache) {
+		n := 10
+		if top := r.URL.Query().Get("top"); top != "" {
+			var err error
+			if n, err = strconv.Atoi(top); err != nil || n < 0 {
+				http.Error(w, "invalid top parameter", http.StatusBadRequest)
+				return
+			}
+		}
+		writeJSON(w, http.StatusOK, adminDetails{adminSummary: summarize(name, cache), TopKeys: cache.top(n)})
+	}))
+	mux.HandleFunc("GET /{name}/keys/{key}", withCache(func(w http.ResponseWriter, r *http.Request, _ string, cache adminCache) {
+		key := r.PathValue("key")
+		value, expires, ok, err := cache.lookup(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !ok {
+			writeJSON(w, http.StatusNotFound, adminEntry{Key: key})
+			return
+		}
+		entry := adminEntry{Key: key, Found: true, Expires: &expires}
+		if config.ShowValues {
+			entry.Value = value
+		}
+		writeJSON(w, http.StatusOK, entry)
+	}))
+	mux.HandleFunc("DELETE /{name}/keys/{key}", withCache(func(w http.ResponseWriter, r *http.Request, _ string, cache adminCache) {
+		if !config.AllowWrites {
+			http.Error(w, "writes are disabled", http.StatusForbidden)
+			return
+		}
+		if err := cache.remove(r.PathValue("key")); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	mux.HandleFunc("POST /{name}/clear", withCache(func(w http.ResponseWriter, r *http.Request, name string, cache adminCache) {
+		if !config.AllowWrites {
+			http.Error(w, "writes are disabled", http.StatusForbidden)
+			return
+		}
+		if r.URL.Query().Get("confirm") != name {
+			http.Error(w, "the confirm parameter must repeat the name of the cache", http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]int{"removed": cache.clear()})
+	}))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if config.Authorize != nil && !config.Authorize(r) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// withCache wraps a handler of a route with a name wildcard, looking up the registered cache with that name.
+func withCache(fn func(w http.ResponseWriter, r *http.Request, name string, cache adminCache)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		registry.RLock()
+		cache, ok := registry.caches[name]
+		registry.RUnlock()
+		if !ok {
+			http.Error(w, "unknown cache "+strconv.Quote(name), http.StatusNotFound)
+			return
+		}
+		fn(w, r, name, cache)
+	}
+}
+
+// summarize returns the summary of the given registered cache.
+func summarize(name string, cache adminCache) adminSummary {
+	stats := cache.stats()
+	return adminSummary{
+		Name:      name,
+		Len:       stats.Len,
+		Capacity:  stats.Capacity,
+		Hits:      stats.Hits,
+		Misses:    stats.Misses,
+		Evictions: stats.Evictions,
+		HitRatio:  stats.HitRatio(),
+	}
+}
+
+// writeJSON writes the given value as a JSON response with the given status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,113 @@
+package ugulru_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func adminRequest(t *testing.T, handler http.Handler, method, target string) (int, map[string]any) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	var body map[string]any
+	if rec.Header().Get("Content-Type") == "application/json" {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	}
+	return rec.Code, body
+}
+
+func TestNewAdminHandler(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](10, time.Minute)
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+	cache.Get("key2")
+	cache.Get("missing")
+	ugulru.Register("users", cache, ugulru.StringKeyCodec[string]{})
+	defer ugulru.Unregister("users")
+
+	handler := http.StripPrefix("/debug/ugulru", ugulru.NewAdminHandler(ugulru.AdminConfig{}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/ugulru/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var caches []map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &caches))
+	require.Len(t, caches, 1)
+	assert.Equal(t, "users", caches[0]["name"])
+	assert.EqualValues(t, 2, caches[0]["len"])
+	assert.EqualValues(t, 0.5, caches[0]["hit_ratio"])
+
+	code, body := adminRequest(t, handler, http.MethodGet, "/debug/ugulru/users?top=1")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []any{map[string]any{"key": "key2", "frequency": 2.0}}, body["top_keys"])
+
+	// Lookups don't expose values by default and don't affect the statistics
+	code, body = adminRequest(t, handler, http.MethodGet, "/debug/ugulru/users/keys/key1")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, true, body["found"])
+	assert.NotContains(t, body, "value")
+	assert.Contains(t, body, "expires")
+	assert.Equal(t, uint64(1), cache.Stats().Hits)
+
+	code, _ = adminRequest(t, handler, http.MethodGet, "/debug/ugulru/users/keys/missing")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = adminRequest(t, handler, http.MethodGet, "/debug/ugulru/unknown")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// Writes are disabled by default
+	code, _ = adminRequest(t, handler, http.MethodDelete, "/debug/ugulru/users/keys/key1")
+	assert.Equal(t, http.StatusForbidden, code)
+	_, ok := cache.Get("key1")
+	assert.True(t, ok)
+}
+
+func TestNewAdminHandler_Writes(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[int, string](10, time.Minute)
+	cache.Put(1, "one")
+	cache.Put(2, "two")
+	cache.Put(3, "three")
+	ugulru.Register("numbers", cache, ugulru.IntKeyCodec[int]{})
+	defer ugulru.Unregister("numbers")
+
+	handler := ugulru.NewAdminHandler(ugulru.AdminConfig{
+		AllowWrites: true,
+		ShowValues:  true,
+		Authorize: func(r *http.Request) bool {
+			return r.Header.Get("X-Admin") == "yes"
+		},
+	})
+	authorized := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set("X-Admin", "yes")
+		handler.ServeHTTP(w, r)
+	})
+
+	code, _ := adminRequest(t, handler, http.MethodGet, "/numbers")
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, body := adminRequest(t, authorized, http.MethodGet, "/numbers/keys/1")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "one", body["value"])
+	code, _ = adminRequest(t, authorized, http.MethodGet, "/numbers/keys/one")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = adminRequest(t, authorized, http.MethodDelete, "/numbers/keys/1")
+	assert.Equal(t, http.StatusNoContent, code)
+	_, ok := cache.Get(1)
+	assert.False(t, ok)
+
+	// Clearing requires a confirmation
+	code, _ = adminRequest(t, authorized, http.MethodPost, "/numbers/clear")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, body = adminRequest(t, authorized, http.MethodPost, "/numbers/clear?confirm=numbers")
+	assert.Equal(t, http.StatusOK, code)
+	assert.EqualValues(t, 2, body["removed"])
+	assert.Zero(t, cache.Stats().Len)
+}
@@ -0,0 +1,76 @@
+package ugulru
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is the error a Store returns when the key doesn't exist.
+var ErrNotFound = errors.New("ugulru: not found")
+
+// Store is a backing store, such as a database, fronted by a cache.
+type Store[K comparable, V any] interface {
+	// Get returns the value stored for the given key, or ErrNotFound if there is none.
+	Get(ctx context.Context, key K) (V, error)
+	// Set stores the value for the given key.
+	Set(ctx context.Context, key K, value V) error
+	// Delete deletes the value stored for the given key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key K) error
+}
+
+// CacheAside pairs a cache with the store it caches and encodes the cache-aside discipline: reads go through the cache
+// and fall back to the store, while writes go to the store first and then invalidate the cache instead of updating it,
+// so a concurrent read can't leave a stale value behind for longer than one read.
+//
+// Optionally, the cache is invalidated a second time after a delay (double delete), which also covers reads that
+// started before a write and stored the old value after the first invalidation.
+type CacheAside[K comparable, V any] struct {
+	cache Cache[K, V]
+	store Store[K, V]
+	delay time.Duration
+}
+
+// NewCacheAside creates a new cache-aside wrapper around the given cache and store. If doubleDeleteDelay is positive,
+// every invalidation is repeated after that delay.
+func NewCacheAside[K comparable, V any](cache Cache[K, V], store Store[K, V], doubleDeleteDelay time.Duration) *CacheAside[K, V] {
+	return &CacheAside[K, V]{cache: cache, store: store, delay: doubleDeleteDelay}
+}
+
+// GetThrough returns the value for the given key from the cache, loading it from the store on a miss.
+func (a *CacheAside[K, V]) GetThrough(ctx context.Context, key K) (V, error) {
+	return a.cache.Load(key, func() (V, error) {
+		return a.store.Get(ctx, key)
+	})
+}
+
+// WriteThrough stores the value for the given key in the store and then invalidates the cached value. The cache is
+// left untouched if the store fails.
+func (a *CacheAside[K, V]) WriteThrough(ctx context.Context, key K, value V) error {
+	if err := a.store.Set(ctx, key, value); err != nil {
+		return err
+	}
+	a.Invalidate(key)
+	return nil
+}
+
+// Delete deletes the value for the given key from the store and then invalidates the cached value. The cache is left
+// untouched if the store fails.
+func (a *CacheAside[K, V]) Delete(ctx context.Context, key K) error {
+	if err := a.store.Delete(ctx, key); err != nil {
+		return err
+	}
+	a.Invalidate(key)
+	return nil
+}
+
+// Invalidate removes the cached value for the given key, and removes it again after the double delete delay if one is
+// set.
+func (a *CacheAside[K, V]) Invalidate(key K) {
+	a.cache.Remove(key)
+	if a.delay > 0 {
+		time.AfterFunc(a.delay, func() {
+			a.cache.Remove(key)
+		})
+	}
+}
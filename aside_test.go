@@ -0,0 +1,113 @@
+package ugulru_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+// mapStore is a Store backed by a map, for tests.
+type mapStore[K comparable, V any] struct {
+	mu   sync.Mutex
+	data map[K]V
+	err  error
+	gets int
+}
+
+func newMapStore[K comparable, V any]() *mapStore[K, V] {
+	return &mapStore[K, V]{data: make(map[K]V)}
+}
+
+func (s *mapStore[K, V]) Get(ctx context.Context, key K) (V, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.gets++
+	value, ok := s.data[key]
+	if !ok {
+		return value, ugulru.ErrNotFound
+	}
+	return value, nil
+}
+
+func (s *mapStore[K, V]) Set(ctx context.Context, key K, value V) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	s.data[key] = value
+	return nil
+}
+
+func (s *mapStore[K, V]) Delete(ctx context.Context, key K) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	delete(s.data, key)
+	return nil
+}
+
+func TestCacheAside(t *testing.T) {
+	ctx := context.Background()
+	store := newMapStore[string, int]()
+	cache := ugulru.NewInMemoryCache[string, int](10, 5*time.Minute)
+	aside := ugulru.NewCacheAside[string, int](cache, store, 0)
+
+	_, err := aside.GetThrough(ctx, "key1")
+	assert.ErrorIs(t, err, ugulru.ErrNotFound)
+
+	assert.NoError(t, aside.WriteThrough(ctx, "key1", 1))
+	_, ok := cache.Get("key1")
+	assert.False(t, ok, "writes should invalidate rather than populate the cache")
+
+	value, err := aside.GetThrough(ctx, "key1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+	value, err = aside.GetThrough(ctx, "key1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, 2, store.gets)
+
+	assert.NoError(t, aside.WriteThrough(ctx, "key1", 2))
+	value, err = aside.GetThrough(ctx, "key1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, value)
+
+	// A failed write leaves the cache untouched
+	store.err = errors.New("store error")
+	assert.Error(t, aside.WriteThrough(ctx, "key1", 3))
+	assert.Error(t, aside.Delete(ctx, "key1"))
+	value, ok = cache.Get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+
+	store.err = nil
+	assert.NoError(t, aside.Delete(ctx, "key1"))
+	_, err = aside.GetThrough(ctx, "key1")
+	assert.ErrorIs(t, err, ugulru.ErrNotFound)
+}
+
+func TestCacheAside_DoubleDelete(t *testing.T) {
+	ctx := context.Background()
+	store := newMapStore[string, int]()
+	cache := ugulru.NewInMemoryCache[string, int](10, 5*time.Minute)
+	aside := ugulru.NewCacheAside[string, int](cache, store, 50*time.Millisecond)
+
+	assert.NoError(t, aside.WriteThrough(ctx, "key1", 2))
+
+	// A stale value stored by a read racing with the write is removed by the second delete
+	cache.Put("key1", 1)
+	time.Sleep(100 * time.Millisecond)
+	_, ok := cache.Get("key1")
+	assert.False(t, ok)
+}
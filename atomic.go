@@ -0,0 +1,132 @@
+package ugulru
+
+import "context"
+
+// GetOrPut returns the existing value for the key if present. Otherwise, it stores and returns the given value. The
+// loaded result is true if the value was loaded, false if stored. It matches the semantics of sync.Map.LoadOrStore.
+func (c *InMemoryCache[K, V]) GetOrPut(key K, value V) (actual V, loaded bool) {
+	c.throttle()
+	defer c.unlock(c.lock())
+
+	if entry, ok := c.get(key); ok {
+		return entry.value, true
+	}
+	_, _ = c.writeThrough(context.Background(), key, value, nil)
+	return value, false
+}
+
+// Update atomically reads, transforms and writes the entry with the given key. The function receives the current
+// value and whether the key exists in the cache, and returns the new value and whether to keep it: true stores the
+// value, false deletes the key. Update returns the value returned by the function.
+func (c *InMemoryCache[K, V]) Update(key K, fn func(old V, exists bool) (V, bool)) V {
+	c.throttle()
+	defer c.unlock(c.lock())
+
+	var old V
+	entry, exists := c.get(key)
+	if exists {
+		old = entry.value
+	}
+	value, keep := fn(old, exists)
+	if keep {
+		_, _ = c.writeThrough(context.Background(), key, value, nil)
+	} else {
+		_ = c.deleteThrough(context.Background(), key)
+	}
+	return value
+}
+
+// Swap stores the value for the key and returns the previous value, if any. The existed result reports whether the
+// key was present in the cache.
+func (c *InMemoryCache[K, V]) Swap(key K, value V) (previous V, existed bool) {
+	c.throttle()
+	defer c.unlock(c.lock())
+
+	if entry, ok := c.get(key); ok {
+		previous, existed = entry.value, true
+	}
+	_, _ = c.writeThrough(context.Background(), key, value, nil)
+	return previous, existed
+}
+
+// WithEqualFunc sets the function used by CompareAndSwap to compare values. It is required for value types that are
+// not comparable, such as slices and maps, or that need a custom notion of equality.
+func WithEqualFunc[K comparable, V any](equal func(a, b V) bool) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.equal = equal
+	}
+}
+
+// CompareAndSwap stores the new value for the key if the current value is equal to old. It returns false if the key
+// doesn't exist in the cache or holds a different value. Values are compared with the function set by WithEqualFunc,
+// or with == otherwise, which panics if the values are not comparable.
+func (c *InMemoryCache[K, V]) CompareAndSwap(key K, old, new V) bool {
+	c.throttle()
+	defer c.unlock(c.lock())
+
+	entry, ok := c.get(key)
+	if !ok || !c.equals(entry.value, old) {
+		return false
+	}
+	_, _ = c.writeThrough(context.Background(), key, new, nil)
+	return true
+}
+
+// equals reports whether the given values are equal.
+func (c *InMemoryCache[K, V]) equals(a, b V) bool {
+	if c.equal != nil {
+		return c.equal(a, b)
+	}
+	return any(a) == any(b)
+}
+
+// CompareAndDelete deletes the entry for the key if its value is equal to the given one, so a stale invalidation
+// can't delete a newer value written concurrently. It returns false if the key doesn't exist in the cache or holds a
+// different value. Values are compared like in CompareAndSwap.
+func (c *InMemoryCache[K, V]) CompareAndDelete(key K, value V) bool {
+	c.throttle()
+	defer c.unlock(c.lock())
+
+	entry, ok := c.get(key)
+	if !ok || !c.equals(entry.value, value) {
+		return false
+	}
+	_ = c.deleteThrough(context.Background(), key)
+	return true
+}
+
+// Pop atomically returns and removes the entry for the key, so the value can only be consumed once, e.g. for one-shot
+// tokens.
+func (c *InMemoryCache[K, V]) Pop(key K) (V, bool) {
+	c.throttle()
+	defer c.unlock(c.lock())
+
+	entry, ok := c.get(key)
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	value := entry.value
+	_ = c.deleteThrough(context.Background(), key)
+	return value, true
+}
+
+// PutIfAbsent stores the value for the key only if the key is missing or has expired, and reports whether the value
+// was stored, so later writers can't overwrite the first value.
+func (c *InMemoryCache[K, V]) PutIfAbsent(key K, value V) bool {
+	_, loaded := c.GetOrPut(key, value)
+	return !loaded
+}
+
+// number is a constraint that permits any integer or floating-point type.
+type number interface {
+	integer | ~float32 | ~float64
+}
+
+// Add atomically adds delta to the value of the key, treating a missing key as zero, and returns the new value. Use a
+// negative delta to decrement. It lets rate counters and quotas live in the cache without read-modify-write races.
+func Add[K comparable, V number](c *InMemoryCache[K, V], key K, delta V) V {
+	return c.Update(key, func(old V, _ bool) (V, bool) {
+		return old + delta, true
+	})
+}
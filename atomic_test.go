@@ -0,0 +1,202 @@
+package ugulru_test
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryCache_GetOrPut(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 100*time.Millisecond)
+
+	actual, loaded := cache.GetOrPut("key", 1)
+	assert.False(t, loaded)
+	assert.Equal(t, 1, actual)
+
+	actual, loaded = cache.GetOrPut("key", 2)
+	assert.True(t, loaded)
+	assert.Equal(t, 1, actual)
+
+	time.Sleep(150 * time.Millisecond)
+	actual, loaded = cache.GetOrPut("key", 3)
+	assert.False(t, loaded)
+	assert.Equal(t, 3, actual)
+}
+
+func TestInMemoryCache_GetOrPut_Concurrent(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+
+	var wg sync.WaitGroup
+	stored := make(chan int, 10)
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, loaded := cache.GetOrPut("key", i); !loaded {
+				stored <- i
+			}
+		}()
+	}
+	wg.Wait()
+	close(stored)
+
+	assert.Len(t, stored, 1)
+	value, _ := cache.Get("key")
+	assert.Equal(t, <-stored, value)
+}
+
+func TestInMemoryCache_Update(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, []string](3, 5*time.Minute)
+
+	appendLine := func(line string) func(old []string, exists bool) ([]string, bool) {
+		return func(old []string, exists bool) ([]string, bool) {
+			return append(old, line), true
+		}
+	}
+	assert.Equal(t, []string{"a"}, cache.Update("log", appendLine("a")))
+	assert.Equal(t, []string{"a", "b"}, cache.Update("log", appendLine("b")))
+
+	value, ok := cache.Get("log")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"a", "b"}, value)
+
+	cache.Update("log", func(old []string, exists bool) ([]string, bool) {
+		assert.True(t, exists)
+		return nil, false
+	})
+	_, ok = cache.Get("log")
+	assert.False(t, ok)
+}
+
+func TestInMemoryCache_Update_Concurrent(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cache.Update("counter", func(old int, _ bool) (int, bool) {
+				return old + 1, true
+			})
+		}()
+	}
+	wg.Wait()
+
+	value, _ := cache.Get("counter")
+	assert.Equal(t, 100, value)
+}
+
+func TestInMemoryCache_Swap(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+
+	previous, existed := cache.Swap("key", 1)
+	assert.False(t, existed)
+	assert.Equal(t, 0, previous)
+
+	previous, existed = cache.Swap("key", 2)
+	assert.True(t, existed)
+	assert.Equal(t, 1, previous)
+
+	value, _ := cache.Get("key")
+	assert.Equal(t, 2, value)
+}
+
+func TestInMemoryCache_CompareAndSwap(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+
+	assert.False(t, cache.CompareAndSwap("key", 0, 1))
+	cache.Put("key", 1)
+	assert.False(t, cache.CompareAndSwap("key", 2, 3))
+	assert.True(t, cache.CompareAndSwap("key", 1, 3))
+
+	value, _ := cache.Get("key")
+	assert.Equal(t, 3, value)
+}
+
+func TestInMemoryCache_CompareAndSwap_EqualFunc(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(3, 5*time.Minute, ugulru.WithEqualFunc[string](slices.Equal[[]int]))
+
+	cache.Put("key", []int{1, 2})
+	assert.False(t, cache.CompareAndSwap("key", []int{1}, []int{3}))
+	assert.True(t, cache.CompareAndSwap("key", []int{1, 2}, []int{3}))
+
+	value, _ := cache.Get("key")
+	assert.Equal(t, []int{3}, value)
+}
+
+func TestInMemoryCache_CompareAndDelete(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+
+	assert.False(t, cache.CompareAndDelete("key", 0))
+	cache.Put("key", 1)
+	assert.False(t, cache.CompareAndDelete("key", 2))
+	_, ok := cache.Get("key")
+	assert.True(t, ok)
+
+	assert.True(t, cache.CompareAndDelete("key", 1))
+	_, ok = cache.Get("key")
+	assert.False(t, ok)
+}
+
+func TestInMemoryCache_Pop(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, string](3, 5*time.Minute)
+	cache.Put("token", "secret")
+
+	var wg sync.WaitGroup
+	consumed := make(chan string, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if value, ok := cache.Pop("token"); ok {
+				consumed <- value
+			}
+		}()
+	}
+	wg.Wait()
+	close(consumed)
+
+	assert.Len(t, consumed, 1)
+	assert.Equal(t, "secret", <-consumed)
+	_, ok := cache.Get("token")
+	assert.False(t, ok)
+}
+
+func TestAdd(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int64](3, 5*time.Minute)
+
+	assert.Equal(t, int64(5), ugulru.Add(cache, "quota", 5))
+	assert.Equal(t, int64(3), ugulru.Add(cache, "quota", -2))
+
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ugulru.Add(cache, "requests", 1)
+		}()
+	}
+	wg.Wait()
+
+	value, _ := cache.Get("requests")
+	assert.Equal(t, int64(100), value)
+}
+
+func TestInMemoryCache_PutIfAbsent(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 100*time.Millisecond)
+
+	assert.True(t, cache.PutIfAbsent("key", 1))
+	assert.False(t, cache.PutIfAbsent("key", 2))
+	value, _ := cache.Get("key")
+	assert.Equal(t, 1, value)
+
+	time.Sleep(150 * time.Millisecond)
+	assert.True(t, cache.PutIfAbsent("key", 3))
+	value, _ = cache.Get("key")
+	assert.Equal(t, 3, value)
+}
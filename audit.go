@@ -0,0 +1,114 @@
+package ugulru
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// AuditReport counts the divergences between the levels of a cache and its origin found by an Auditor.
+type AuditReport struct {
+	// Sampled is the number of keys compared.
+	Sampled int
+	// L1Stale is the number of sampled keys whose first-level value differs from the origin, or is missing from it.
+	L1Stale int
+	// L2Stale is the number of sampled keys whose second-level value differs from the origin, or is missing from it.
+	// Keys missing from the second level are not counted.
+	L2Stale int
+	// Errors is the number of sampled keys that couldn't be read from the origin.
+	Errors int
+}
+
+// Auditor verifies the consistency of a tiered or write-through setup in the background: it samples keys of the
+// first-level cache and compares their values with the second level, if any, and with the origin store, so
+// invalidation bugs show up as divergences before customers notice them. Values are compared like in CompareAndSwap.
+// A key written while it is audited may be reported as divergent, so occasional divergences are expected under load.
+type Auditor[K comparable, V any] struct {
+	l1     *InMemoryCache[K, V]
+	l2     Cache[K, V]
+	origin Store[K, V]
+
+	mu    sync.Mutex
+	total AuditReport
+}
+
+// NewAuditor creates a new auditor comparing the given first-level cache with the given second level, which may be nil
+// for a write-through setup, and with the origin store.
+func NewAuditor[K comparable, V any](l1 *InMemoryCache[K, V], l2 Cache[K, V], origin Store[K, V]) *Auditor[K, V] {
+	return &Auditor[K, V]{l1: l1, l2: l2, origin: origin}
+}
+
+// Audit compares up to n keys sampled at random from the live entries of the first level and returns the divergences
+// found. Reading the sampled values doesn't change the recency of the first-level entries.
+func (a *Auditor[K, V]) Audit(ctx context.Context, n int) AuditReport {
+	var report AuditReport
+	for _, s := range a.sample(n) {
+		report.Sampled++
+		want, err := a.origin.Get(ctx, s.Key)
+		missing := errors.Is(err, ErrNotFound)
+		if err != nil && !missing {
+			report.Errors++
+			continue
+		}
+		if missing || !a.l1.equals(s.Value, want) {
+			report.L1Stale++
+		}
+		if a.l2 == nil {
+			continue
+		}
+		if value, ok := a.l2.Get(s.Key); ok && (missing || !a.l1.equals(value, want)) {
+			report.L2Stale++
+		}
+	}
+
+	a.mu.Lock()
+	a.total.Sampled += report.Sampled
+	a.total.L1Stale += report.L1Stale
+	a.total.L2Stale += report.L2Stale
+	a.total.Errors += report.Errors
+	a.mu.Unlock()
+	return report
+}
+
+// Run audits n keys every interval until the context is done.
+func (a *Auditor[K, V]) Run(ctx context.Context, interval time.Duration, n int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			a.Audit(ctx, n)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Report returns the divergences found by all the audits so far.
+func (a *Auditor[K, V]) Report() AuditReport {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.total
+}
+
+// sample returns up to n live entries of the first level chosen uniformly at random.
+func (a *Auditor[K, V]) sample(n int) []snapshotEntry[K, V] {
+	if n <= 0 {
+		return nil
+	}
+	sampled := make([]snapshotEntry[K, V], 0, n)
+	seen := 0
+	a.l1.Range(func(key K, value V) bool {
+		seen++
+		if len(sampled) < n {
+			sampled = append(sampled, snapshotEntry[K, V]{Key: key, Value: value})
+		} else if i := rand.IntN(seen); i < n {
+			sampled[i] = snapshotEntry[K, V]{Key: key, Value: value}
+		}
+		return true
+	})
+	return sampled
+}
@@ -0,0 +1,49 @@
+package ugulru_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditor(t *testing.T) {
+	t.Run("Test write-through", func(t *testing.T) {
+		store := newMapStore[string, int]()
+		cache := ugulru.NewInMemoryCache(10, 5*time.Minute, ugulru.WithWriteThrough[string, int](store))
+		for i, key := range []string{"key1", "key2", "key3"} {
+			cache.Put(key, i)
+		}
+		auditor := ugulru.NewAuditor[string, int](cache, nil, store)
+		assert.Equal(t, ugulru.AuditReport{Sampled: 3}, auditor.Audit(context.Background(), 10))
+
+		// A change made behind the back of the cache shows up as a divergence
+		store.mu.Lock()
+		store.data["key1"] = 10
+		delete(store.data, "key2")
+		store.mu.Unlock()
+		assert.Equal(t, ugulru.AuditReport{Sampled: 3, L1Stale: 2}, auditor.Audit(context.Background(), 10))
+		assert.Equal(t, ugulru.AuditReport{}, auditor.Audit(context.Background(), 0))
+		assert.Equal(t, ugulru.AuditReport{Sampled: 6, L1Stale: 2}, auditor.Report())
+	})
+
+	t.Run("Test tiered", func(t *testing.T) {
+		store := newMapStore[string, int]()
+		l1 := ugulru.NewInMemoryCache[string, int](10, time.Minute)
+		l2 := ugulru.NewInMemoryCache[string, int](10, time.Hour)
+		tiered := ugulru.NewTieredCache[string, int](l1, l2)
+		for i, key := range []string{"key1", "key2"} {
+			store.data[key] = i
+			tiered.Put(key, i)
+		}
+
+		l2.Put("key1", 10)
+		auditor := ugulru.NewAuditor[string, int](l1, l2, store)
+		assert.Equal(t, ugulru.AuditReport{Sampled: 2, L2Stale: 1}, auditor.Audit(context.Background(), 10))
+
+		// Only up to n keys are sampled
+		assert.Equal(t, 1, auditor.Audit(context.Background(), 1).Sampled)
+	})
+}
@@ -0,0 +1,245 @@
+// Package badgercache provides a ugulru.Cache backed by a Badger database, for write-heavy persistent caching. Entries
+// expire using the native TTL support of Badger, so expired entries are never returned and their space is reclaimed
+// by the value log garbage collection.
+package badgercache
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/machine23/ugulru"
+)
+
+// Cache is a ugulru.Cache storing its entries in a Badger database. Keys and values are converted to bytes with the
+// given codecs. Database errors are treated as misses by Get and Load, and Put and Remove drop them; use the methods of
+// the database directly when errors matter. Large values can be streamed with PutReader and GetReader. Encoded keys
+// starting with a zero byte are reserved for the chunks of streamed values. It is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	db       *badger.DB
+	ttl      time.Duration
+	keys     ugulru.KeyCodec[K]
+	values   ugulru.Codec[V]
+	maxBytes int64
+
+	mu        sync.Mutex
+	evictedAt int64 // size of the database when entries were last evicted
+}
+
+var _ ugulru.Cache[string, any] = (*Cache[string, any])(nil)
+
+// Option configures a Cache.
+type Option[K comparable, V any] func(*Cache[K, V])
+
+// WithMaxBytes limits the size of the database on disk, as reported by badger.DB.Size, to approximately maxBytes bytes.
+// When the database is larger, Put first runs the value log garbage collection to reclaim the space of expired and
+// overwritten entries, and if the database is still too large, deletes the least recently written entries in proportion
+// to the excess, so as to bring the database to 90% of the limit. Badger refreshes the reported size periodically and
+// reclaims the space of deleted entries during later garbage collections, so the database can briefly grow past the
+// limit.
+func WithMaxBytes[K comparable, V any](maxBytes int64) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.maxBytes = maxBytes
+	}
+}
+
+// New creates a new cache storing its entries in the given open database with the given time-to-live. A TTL of zero
+// means that entries never expire. The database is owned by the caller, who must close it once the cache is no longer
+// used.
+func New[K comparable, V any](db *badger.DB, ttl time.Duration, keys ugulru.KeyCodec[K], values ugulru.Codec[V], opts ...Option[K, V]) *Cache[K, V] {
+	c := &Cache[K, V]{db: db, ttl: ttl, keys: keys, values: values}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Get retrieves the value for the given key, if it exists and hasn't expired.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	value, err := c.get(key)
+	return value, err == nil
+}
+
+// get reads and decodes the value for the given key.
+func (c *Cache[K, V]) get(key K) (V, error) {
+	var value V
+	k, err := c.keys.EncodeKey(key)
+	if err != nil {
+		return value, err
+	}
+	err = c.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(k))
+		if err != nil {
+			return err
+		}
+		if item.UserMeta() == metaStream {
+			data, err := readStream(txn, k, item)
+			if err != nil {
+				return err
+			}
+			value, err = c.values.Decode(data)
+			return err
+		}
+		return item.Value(func(data []byte) error {
+			value, err = c.values.Decode(data)
+			return err
+		})
+	})
+	return value, err
+}
+
+// Put stores the value for the given key with the TTL of the cache.
+func (c *Cache[K, V]) Put(key K, value V) {
+	_ = c.put(key, value)
+}
+
+// put encodes and writes the value for the given key.
+func (c *Cache[K, V]) put(key K, value V) error {
+	if err := c.makeRoom(); err != nil {
+		return err
+	}
+	k, err := c.keys.EncodeKey(key)
+	if err != nil {
+		return err
+	}
+	data, err := c.values.Encode(value)
+	if err != nil {
+		return err
+	}
+	return c.replace(k, c.entry([]byte(k), data))
+}
+
+// entry returns a new entry with the given key and value and the TTL of the cache.
+func (c *Cache[K, V]) entry(key, data []byte) *badger.Entry {
+	e := badger.NewEntry(key, data)
+	if c.ttl > 0 {
+		e = e.WithTTL(c.ttl)
+	}
+	return e
+}
+
+// makeRoom evicts entries if the database is over its size limit, running the value log garbage collection first.
+// Once entries have been evicted, it waits for Badger to refresh the size of the database before evicting again.
+func (c *Cache[K, V]) makeRoom() error {
+	if c.maxBytes <= 0 {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lsm, vlog := c.db.Size()
+	if size := lsm + vlog; size <= c.maxBytes || size == c.evictedAt {
+		return nil
+	}
+	_ = c.db.RunValueLogGC(0.5)
+	lsm, vlog = c.db.Size()
+	if lsm+vlog <= c.maxBytes {
+		return nil
+	}
+	c.evictedAt = lsm + vlog
+	return c.evict(0.9 * float64(c.maxBytes) / float64(lsm+vlog))
+}
+
+// evict deletes the least recently written entries until the estimated size of the remaining ones is at most the given
+// fraction of their current size. Streamed values are evicted with their chunks, which count towards their size.
+func (c *Cache[K, V]) evict(keep float64) error {
+	type entry struct {
+		key     []byte
+		version uint64
+		size    int64
+		gen     uint64
+		chunks  [][]byte
+	}
+	var entries []entry
+	chunks := make(map[string][]entry)
+	err := c.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			e := entry{key: item.KeyCopy(nil), version: item.Version(), size: item.EstimatedSize()}
+			if k, gen, ok := chunkOwner(e.key); ok {
+				e.gen = gen
+				chunks[k] = append(chunks[k], e)
+				continue
+			}
+			if item.UserMeta() == metaStream {
+				header, err := readHeader(item)
+				if err != nil {
+					return err
+				}
+				e.gen = header.gen
+			}
+			entries = append(entries, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// Chunks of another generation than their entry belong to a value being streamed and are left alone
+	var size int64
+	for i := range entries {
+		for _, chunk := range chunks[string(entries[i].key)] {
+			if chunk.gen == entries[i].gen {
+				entries[i].chunks = append(entries[i].chunks, chunk.key)
+				entries[i].size += chunk.size
+			}
+		}
+		size += entries[i].size
+	}
+
+	target := int64(float64(size) * keep)
+	slices.SortFunc(entries, func(a, b entry) int {
+		return cmp.Compare(a.version, b.version)
+	})
+	batch := c.db.NewWriteBatch()
+	defer batch.Cancel()
+	for _, e := range entries {
+		if size <= target {
+			break
+		}
+		for _, key := range append(e.chunks, e.key) {
+			if err := batch.Delete(key); err != nil {
+				return err
+			}
+		}
+		size -= e.size
+	}
+	return batch.Flush()
+}
+
+// Remove deletes the entry with the given key.
+func (c *Cache[K, V]) Remove(key K) {
+	k, err := c.keys.EncodeKey(key)
+	if err != nil {
+		return
+	}
+	_ = c.replace(k, nil)
+}
+
+// RemoveExpired reclaims the space used by expired entries by running the value log garbage collection until it has
+// nothing left to rewrite. Expired entries are never returned even if it isn't called.
+func (c *Cache[K, V]) RemoveExpired() {
+	for c.db.RunValueLogGC(0.5) == nil {
+	}
+}
+
+// Load retrieves the value for the given key. If the key doesn't exist or has expired, the loader is called to load
+// the value, which is then stored in the cache and returned. The loaded value is returned even if it can't be stored.
+func (c *Cache[K, V]) Load(key K, loader func() (V, error)) (V, error) {
+	if value, err := c.get(key); err == nil {
+		return value, nil
+	}
+	value, err := loader()
+	if err != nil {
+		return value, err
+	}
+	_ = c.put(key, value)
+	return value, nil
+}
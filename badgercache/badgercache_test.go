@@ -0,0 +1,160 @@
+package badgercache_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/machine23/ugulru"
+	"github.com/machine23/ugulru/badgercache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openDB(t *testing.T, opts badger.Options) *badger.DB {
+	t.Helper()
+
+	db, err := badger.Open(opts.WithLogger(nil))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+	})
+	return db
+}
+
+func TestCache(t *testing.T) {
+	db := openDB(t, badger.DefaultOptions("").WithInMemory(true))
+	cache := badgercache.New[string, int](db, time.Minute, ugulru.StringKeyCodec[string]{}, ugulru.GobCodec[int]{})
+
+	_, ok := cache.Get("key1")
+	assert.False(t, ok)
+
+	cache.Put("key1", 1)
+	value, ok := cache.Get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	cache.Remove("key1")
+	_, ok = cache.Get("key1")
+	assert.False(t, ok)
+
+	loads := 0
+	loader := func() (int, error) {
+		loads++
+		return 2, nil
+	}
+	for range 2 {
+		value, err := cache.Load("key2", loader)
+		require.NoError(t, err)
+		assert.Equal(t, 2, value)
+	}
+	assert.Equal(t, 1, loads)
+}
+
+func TestCache_TTL(t *testing.T) {
+	db := openDB(t, badger.DefaultOptions("").WithInMemory(true))
+	cache := badgercache.New[string, int](db, time.Second, ugulru.StringKeyCodec[string]{}, ugulru.GobCodec[int]{})
+
+	cache.Put("key1", 1)
+	_, ok := cache.Get("key1")
+	assert.True(t, ok)
+
+	// Badger expires entries with a precision of one second
+	time.Sleep(2 * time.Second)
+	_, ok = cache.Get("key1")
+	assert.False(t, ok)
+}
+
+func TestWithMaxBytes(t *testing.T) {
+	dir := t.TempDir()
+	db, err := badger.Open(badger.DefaultOptions(dir).WithLogger(nil))
+	require.NoError(t, err)
+	cache := badgercache.New[string, int](db, time.Minute, ugulru.StringKeyCodec[string]{}, ugulru.GobCodec[int]{})
+	for i := range 10 {
+		cache.Put(fmt.Sprint("key", i), i)
+	}
+	require.NoError(t, db.Close())
+
+	// Badger computes the size of the database when it is opened
+	db = openDB(t, badger.DefaultOptions(dir))
+	lsm, vlog := db.Size()
+	cache = badgercache.New(db, time.Minute, ugulru.StringKeyCodec[string]{}, ugulru.GobCodec[int]{},
+		badgercache.WithMaxBytes[string, int](lsm+vlog-1))
+
+	// The write is stored, and the least recently written entries are evicted to make room for it
+	cache.Put("new", 10)
+	value, ok := cache.Get("new")
+	assert.True(t, ok, "writes should be stored once the database is full")
+	assert.Equal(t, 10, value)
+	_, ok = cache.Get("key0")
+	assert.False(t, ok)
+	_, ok = cache.Get("key9")
+	assert.True(t, ok)
+
+	// Writes keep being stored until Badger refreshes the size of the database
+	for i := range 10 {
+		cache.Put(fmt.Sprint("more", i), i)
+		_, ok := cache.Get(fmt.Sprint("more", i))
+		assert.True(t, ok)
+	}
+}
+
+func TestCache_Reader(t *testing.T) {
+	db := openDB(t, badger.DefaultOptions("").WithInMemory(true))
+	cache := badgercache.New[string, []byte](db, time.Minute, ugulru.StringKeyCodec[string]{}, ugulru.BytesCodec[[]byte]{})
+
+	_, ok := cache.GetReader("blob")
+	assert.False(t, ok)
+
+	// The blob spans several chunks
+	blob := bytes.Repeat([]byte("0123456789"), 300_000)
+	require.NoError(t, cache.PutReader("blob", bytes.NewReader(blob), int64(len(blob))))
+	r, ok := cache.GetReader("blob")
+	require.True(t, ok)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, blob, data)
+	value, ok := cache.Get("blob")
+	assert.True(t, ok)
+	assert.Equal(t, blob, value)
+
+	// A size mismatch leaves the previous value in place
+	assert.Error(t, cache.PutReader("blob", strings.NewReader("short"), 10))
+	assert.Error(t, cache.PutReader("blob", strings.NewReader("too long"), 3))
+	value, ok = cache.Get("blob")
+	assert.True(t, ok)
+	assert.Equal(t, blob, value)
+
+	// Values written with Put can be streamed too, and replacing or removing a streamed value deletes its chunks
+	cache.Put("blob", []byte("small"))
+	r, ok = cache.GetReader("blob")
+	require.True(t, ok)
+	data, err = io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("small"), data)
+	require.NoError(t, cache.PutReader("blob", bytes.NewReader(blob), int64(len(blob))))
+	cache.Remove("blob")
+	_, ok = cache.GetReader("blob")
+	assert.False(t, ok)
+	assert.Equal(t, 0, countKeys(t, db))
+}
+
+func countKeys(t *testing.T, db *badger.DB) int {
+	t.Helper()
+
+	n := 0
+	require.NoError(t, db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			n++
+		}
+		return nil
+	}))
+	return n
+}
@@ -0,0 +1,241 @@
+package badgercache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+const (
+	// metaStream marks an entry holding the header of a streamed value instead of the value itself.
+	metaStream byte = 1
+	// chunkSize is the size of the chunks streamed values are split into, below the default value threshold of Badger,
+	// which values kept in memory must not reach.
+	chunkSize = 1 << 19
+	// headerSize is the size of an encoded streamHeader.
+	headerSize = 20
+)
+
+// streamHeader describes a streamed value, stored in chunks under keys derived from the key of the value and the
+// generation of the write, so a value being replaced never mixes with the chunks of the new one.
+type streamHeader struct {
+	gen    uint64
+	chunks uint32
+	size   int64
+}
+
+func (h streamHeader) encode() []byte {
+	data := binary.BigEndian.AppendUint64(nil, h.gen)
+	data = binary.BigEndian.AppendUint32(data, h.chunks)
+	return binary.BigEndian.AppendUint64(data, uint64(h.size))
+}
+
+// readHeader decodes the header stored in the given item.
+func readHeader(item *badger.Item) (streamHeader, error) {
+	var h streamHeader
+	err := item.Value(func(data []byte) error {
+		if len(data) != headerSize {
+			return errors.New("badgercache: corrupt stream header")
+		}
+		h.gen = binary.BigEndian.Uint64(data)
+		h.chunks = binary.BigEndian.Uint32(data[8:])
+		h.size = int64(binary.BigEndian.Uint64(data[12:]))
+		if h.size < 0 || h.size > int64(h.chunks)*chunkSize {
+			return errors.New("badgercache: corrupt stream header")
+		}
+		return nil
+	})
+	return h, err
+}
+
+// chunkKey returns the key of the given chunk of the value of the encoded key k written with the given generation.
+func chunkKey(k string, gen uint64, i uint32) []byte {
+	key := make([]byte, 0, len(k)+14)
+	key = append(key, 0)
+	key = append(key, k...)
+	key = append(key, 0)
+	key = binary.BigEndian.AppendUint64(key, gen)
+	return binary.BigEndian.AppendUint32(key, i)
+}
+
+// chunkOwner returns the encoded key and the generation of the value the given chunk key belongs to, or false if key
+// is not a chunk key.
+func chunkOwner(key []byte) (string, uint64, bool) {
+	if len(key) < 14 || key[0] != 0 {
+		return "", 0, false
+	}
+	owner := key[1 : len(key)-13]
+	return string(owner), binary.BigEndian.Uint64(key[len(key)-12:]), true
+}
+
+// PutReader stores the size bytes read from r as the encoded value for the given key with the TTL of the cache,
+// without holding them all in memory: the value is written in chunks of 512 KiB, and only becomes visible once all of
+// them are written. Get decodes the bytes with the value codec, so use ugulru.BytesCodec to read them back unchanged.
+// If r holds fewer or more than size bytes, nothing is stored and an error is returned.
+func (c *Cache[K, V]) PutReader(key K, r io.Reader, size int64) error {
+	if size < 0 {
+		return fmt.Errorf("badgercache: invalid size %d", size)
+	}
+	if err := c.makeRoom(); err != nil {
+		return err
+	}
+	k, err := c.keys.EncodeKey(key)
+	if err != nil {
+		return err
+	}
+
+	header := streamHeader{gen: rand.Uint64() | 1, size: size}
+	buf := make([]byte, min(size, chunkSize))
+	for n := int64(0); n < size; header.chunks++ {
+		m, err := io.ReadFull(r, buf[:min(size-n, chunkSize)])
+		if err == nil {
+			err = c.db.Update(func(txn *badger.Txn) error {
+				return txn.SetEntry(c.entry(chunkKey(k, header.gen, header.chunks), buf[:m]))
+			})
+		}
+		if err != nil {
+			_ = c.deleteChunks(k, header.gen, header.chunks)
+			return fmt.Errorf("badgercache: stream value: %w", err)
+		}
+		n += int64(m)
+	}
+	if m, _ := io.ReadFull(r, make([]byte, 1)); m > 0 {
+		_ = c.deleteChunks(k, header.gen, header.chunks)
+		return fmt.Errorf("badgercache: stream value: more than %d bytes", size)
+	}
+	return c.replace(k, c.entry([]byte(k), header.encode()).WithMeta(metaStream))
+}
+
+// GetReader returns a reader streaming the encoded value for the given key, if it exists and hasn't expired, and
+// whether it was found. A value written with PutReader is read one chunk at a time. Reading fails if the value is
+// replaced, removed or evicted while it is being read. Closing the reader is not required but is good practice.
+func (c *Cache[K, V]) GetReader(key K) (io.ReadCloser, bool) {
+	k, err := c.keys.EncodeKey(key)
+	if err != nil {
+		return nil, false
+	}
+	var r io.ReadCloser
+	err = c.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(k))
+		if err != nil {
+			return err
+		}
+		if item.UserMeta() == metaStream {
+			header, err := readHeader(item)
+			r = &chunkReader{db: c.db, k: k, header: header}
+			return err
+		}
+		data, err := item.ValueCopy(nil)
+		r = io.NopCloser(bytes.NewReader(data))
+		return err
+	})
+	return r, err == nil
+}
+
+// chunkReader reads a streamed value one chunk at a time.
+type chunkReader struct {
+	db     *badger.DB
+	k      string
+	header streamHeader
+	next   uint32
+	buf    []byte
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.next == r.header.chunks {
+			return 0, io.EOF
+		}
+		err := r.db.View(func(txn *badger.Txn) error {
+			item, err := txn.Get(chunkKey(r.k, r.header.gen, r.next))
+			if err != nil {
+				return err
+			}
+			r.buf, err = item.ValueCopy(r.buf[:0])
+			return err
+		})
+		if err != nil {
+			return 0, fmt.Errorf("badgercache: read chunk: %w", err)
+		}
+		r.next++
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *chunkReader) Close() error {
+	r.buf = nil
+	r.next = r.header.chunks
+	return nil
+}
+
+// readStream reads the whole streamed value whose header is stored in the given item of the encoded key k.
+func readStream(txn *badger.Txn, k string, item *badger.Item) ([]byte, error) {
+	header, err := readHeader(item)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, 0, header.size)
+	for i := range header.chunks {
+		chunk, err := txn.Get(chunkKey(k, header.gen, i))
+		if err != nil {
+			return nil, err
+		}
+		err = chunk.Value(func(value []byte) error {
+			data = append(data, value...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// replace writes the given entry for the encoded key k, or deletes the key if e is nil, and then deletes the chunks
+// of the streamed value it replaced, if any.
+func (c *Cache[K, V]) replace(k string, e *badger.Entry) error {
+	var old streamHeader
+	update := func(txn *badger.Txn) error {
+		old = streamHeader{}
+		item, err := txn.Get([]byte(k))
+		switch {
+		case err == nil && item.UserMeta() == metaStream:
+			if old, err = readHeader(item); err != nil {
+				return err
+			}
+		case err != nil && !errors.Is(err, badger.ErrKeyNotFound):
+			return err
+		}
+		if e == nil {
+			return txn.Delete([]byte(k))
+		}
+		return txn.SetEntry(e)
+	}
+	err := c.db.Update(update)
+	for errors.Is(err, badger.ErrConflict) {
+		err = c.db.Update(update)
+	}
+	if err != nil || old.chunks == 0 {
+		return err
+	}
+	return c.deleteChunks(k, old.gen, old.chunks)
+}
+
+// deleteChunks deletes the given number of chunks of the value of the encoded key k written with the given generation.
+func (c *Cache[K, V]) deleteChunks(k string, gen uint64, chunks uint32) error {
+	batch := c.db.NewWriteBatch()
+	defer batch.Cancel()
+	for i := range chunks {
+		if err := batch.Delete(chunkKey(k, gen, i)); err != nil {
+			return err
+		}
+	}
+	return batch.Flush()
+}
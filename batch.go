@@ -0,0 +1,238 @@
+package ugulru
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// RemoveMany deletes the entries with the given keys from the cache under a single lock acquisition. It returns the
+// number of live entries that were removed; keys that were missing or already expired are not counted.
+func (c *InMemoryCache[K, V]) RemoveMany(keys []K) (removed int) {
+	return c.RemoveManyFunc(keys, nil)
+}
+
+// RemoveManyFunc works like RemoveMany but also reports the result for every key to the given function, if not nil:
+// whether a live entry was removed, and the error returned by the store set by WithWriteThrough, if any. A key given
+// several times is only reported as removed once. The function is called with the lock held, so it must not call any
+// methods of the cache.
+func (c *InMemoryCache[K, V]) RemoveManyFunc(keys []K, result func(key K, removed bool, err error)) (removed int) {
+	c.throttle()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// While the cache is quiesced, removed entries stay in place until Unquiesce
+	var seen map[K]struct{}
+	if c.quiesced {
+		seen = make(map[K]struct{}, len(keys))
+	}
+	for _, key := range keys {
+		e, ok := c.cache[key]
+		live := ok && !c.expired(e) || c.overflowedLive(key)
+		if seen != nil {
+			if _, ok := seen[key]; ok {
+				live = false
+			}
+			seen[key] = struct{}{}
+		}
+		if live {
+			removed++
+		}
+		err := c.deleteThrough(context.Background(), key)
+		if result != nil {
+			result(key, live, err)
+		}
+	}
+	return removed
+}
+
+// RemoveMulti works like RemoveMany but takes the keys as variadic arguments, which is convenient when a single
+// upstream change maps to a handful of known cache keys.
+func (c *InMemoryCache[K, V]) RemoveMulti(keys ...K) (removed int) {
+	return c.RemoveMany(keys)
+}
+
+// GetMulti retrieves the values of the given keys under a single lock acquisition. It returns the live entries found;
+// missing and expired keys are absent from the result.
+func (c *InMemoryCache[K, V]) GetMulti(keys []K) map[K]V {
+	defer c.unlock(c.lock())
+
+	found := make(map[K]V, len(keys))
+	for _, key := range keys {
+		if entry, ok := c.get(key); ok {
+			found[key] = entry.value
+		}
+	}
+	return found
+}
+
+// PutMulti inserts or updates the given entries under a single lock acquisition. Eviction is performed once, after all
+// the entries have been written, so if there are more entries than the capacity of the cache, some of the new entries
+// are evicted as well.
+func (c *InMemoryCache[K, V]) PutMulti(entries map[K]V) {
+	c.throttle()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, value := range entries {
+		if err := c.writeBacking(context.Background(), key, value); err != nil {
+			c.remove(key)
+			continue
+		}
+		if c.quiesced {
+			c.buffer(pendingWrite[K, V]{key: key, value: value})
+			continue
+		}
+		if c.overflow != nil {
+			c.dropOverflow(key)
+		}
+		if e, ok := c.cache[key]; ok {
+			c.update(e, value)
+		} else {
+			c.insert(key, value)
+		}
+	}
+	if !c.quiesced {
+		c.trim()
+	}
+}
+
+// LoadMulti retrieves the values of the given keys, returning the cached ones and calling the loader once with all the
+// missing or expired keys to load the rest. Every key goes through the same steps as with Load, such as the overflow
+// store, the store set by WithWriteThrough, the circuit breaker, negative caching and stale values on errors; only the
+// loader call is shared, and bounded as a whole by the load timeout. The loaded values are stored in the cache. Keys
+// the loader doesn't return a value for are absent from the result, and the values it returns for keys that were not
+// requested are ignored. If some keys fail to load, including with a cached negative result, LoadMulti returns the
+// other values and the errors joined.
+func (c *InMemoryCache[K, V]) LoadMulti(keys []K, loader func(missing []K) (map[K]V, error)) (map[K]V, error) {
+	defer c.unlock(c.lock())
+
+	ctx := context.Background()
+	found := make(map[K]V, len(keys))
+	var missing []K
+	var errs []error
+	seen := make(map[K]struct{}, len(keys))
+	for _, key := range keys {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		single := func() (V, error) {
+			return loadSingle(key, loader)
+		}
+		if value, ok, err := c.cached(key, single); ok {
+			if err != nil {
+				errs = append(errs, err)
+			} else {
+				found[key] = value
+			}
+			continue
+		}
+		if value, ok := c.readOverflow(key); ok {
+			found[key] = value
+			continue
+		}
+		if c.breaker != nil && !c.breaker.allow(key) {
+			errs = append(errs, ErrCircuitOpen)
+			continue
+		}
+		if c.backing != nil {
+			value, err := c.callWithTimeout(ctx, c.readThrough(key, func(context.Context) (V, error) {
+				var zero V
+				return zero, ErrNotFound
+			}))
+			if !errors.Is(err, ErrNotFound) {
+				c.loadedMulti(found, key, value, err, 0)
+				errs = append(errs, err)
+				continue
+			}
+		}
+		missing = append(missing, key)
+	}
+	if len(missing) == 0 {
+		return found, errors.Join(errs...)
+	}
+
+	start := time.Now()
+	loaded, err := withTimeout(ctx, c.loadTimeout, func(context.Context) (map[K]V, error) {
+		return loader(missing)
+	})
+	penalty := time.Since(start)
+	for _, key := range missing {
+		value, ok := loaded[key]
+		if ok || err != nil {
+			c.loadedMulti(found, key, value, err, penalty)
+		} else if c.breaker != nil {
+			c.breaker.record(key, true)
+		}
+	}
+	return found, errors.Join(append(errs, err)...)
+}
+
+// loadedMulti stores the result of loading the given key for LoadMulti like loaded, and adds the value to found if
+// there is one to return. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) loadedMulti(found map[K]V, key K, value V, err error, penalty time.Duration) {
+	if c.breaker != nil {
+		c.breaker.record(key, err == nil || (c.isNegative != nil && c.isNegative(err)))
+	}
+	if err == nil {
+		c.loaded(key, value, nil, penalty)
+		found[key] = value
+		return
+	}
+	if c.storeNegative(key, err) {
+		return
+	}
+	if stale, ok := c.staleOnError(key); ok {
+		found[key] = stale
+	}
+}
+
+// loadSingle loads the value of a single key with a batch loader, e.g. to refresh it in the background, and returns
+// ErrNotFound if the loader doesn't return it.
+func loadSingle[K comparable, V any](key K, loader func(missing []K) (map[K]V, error)) (V, error) {
+	loaded, err := loader([]K{key})
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	value, ok := loaded[key]
+	if !ok {
+		return value, ErrNotFound
+	}
+	return value, nil
+}
+
+// RemoveIf removes all the live entries for which the predicate returns true, e.g. all the entries of a deleted
+// tenant, and returns the number of removed entries. With WithOverflow, the entries in the overflow store are read
+// back to evaluate the predicate. With WithWriteThrough, only the matching entries held by the cache are deleted from
+// the store. The cache is locked while the predicate is evaluated, so it must not call any methods of the cache.
+func (c *InMemoryCache[K, V]) RemoveIf(predicate func(key K, value V) bool) (removed int) {
+	c.throttle()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.removeIf(predicate, func(key K) {
+		_ = c.deleteThrough(context.Background(), key)
+	})
+}
+
+// removeIf removes all the live entries for which the predicate returns true with the given function, and returns
+// the number of removed entries. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) removeIf(predicate func(key K, value V) bool, remove func(key K)) (removed int) {
+	for entry := c.list.Front(); entry != nil; {
+		next := entry.Next()
+		if !c.expired(entry) && entry.err == nil && predicate(entry.key, entry.value) {
+			removed++
+			remove(entry.key)
+		}
+		entry = next
+	}
+	if c.overflow != nil {
+		for _, key := range c.overflowedIf(predicate) {
+			removed++
+			remove(key)
+		}
+	}
+	return removed
+}
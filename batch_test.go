@@ -0,0 +1,199 @@
+package ugulru_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+var errGone = errors.New("gone")
+
+func TestInMemoryCache_RemoveMany(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+	cache.Put("key3", 3)
+
+	removed := cache.RemoveMany([]string{"key1", "key3", "key4", "key1"})
+	assert.Equal(t, 2, removed) // missing and duplicate keys are not counted
+
+	_, ok := cache.Get("key1")
+	assert.False(t, ok)
+	_, ok = cache.Get("key3")
+	assert.False(t, ok)
+	value, ok := cache.Get("key2")
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+
+	assert.Equal(t, 0, cache.RemoveMany(nil))
+}
+
+func TestInMemoryCache_RemoveManyFunc(t *testing.T) {
+	store := newMapStore[string, int]()
+	cache := ugulru.NewInMemoryCache(3, 5*time.Minute, ugulru.WithWriteThrough[string, int](store))
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+
+	errUnavailable := errors.New("unavailable")
+	store.mu.Lock()
+	store.err = errUnavailable
+	store.mu.Unlock()
+
+	results := make(map[string]bool)
+	removed := cache.RemoveManyFunc([]string{"key1", "key3"}, func(key string, removed bool, err error) {
+		results[key] = removed
+		assert.ErrorIs(t, err, errUnavailable, key)
+	})
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, map[string]bool{"key1": true, "key3": false}, results)
+}
+
+func TestInMemoryCache_RemoveManyQuiesced(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+
+	cache.Quiesce()
+	assert.Equal(t, 1, cache.RemoveMany([]string{"key1", "key1"}), "a buffered removal should be counted once")
+	cache.Unquiesce()
+	_, ok := cache.Get("key1")
+	assert.False(t, ok)
+}
+
+func TestInMemoryCache_GetMulti(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+	cache.Put("key3", 3)
+
+	found := cache.GetMulti([]string{"key1", "key3", "key4"})
+	assert.Equal(t, map[string]int{"key1": 1, "key3": 3}, found)
+
+	// Found entries are marked as recently used
+	cache.Put("key4", 4)
+	_, ok := cache.Get("key2")
+	assert.False(t, ok, "key2 should be evicted")
+
+	assert.Empty(t, cache.GetMulti(nil))
+}
+
+func TestInMemoryCache_PutMulti(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+
+	cache.PutMulti(map[string]int{"key2": 20, "key3": 3})
+	assert.Equal(t, map[string]int{"key1": 1, "key2": 20, "key3": 3}, cache.GetMulti([]string{"key1", "key2", "key3"}))
+
+	// The least recently used entries are evicted at the end
+	cache.PutMulti(map[string]int{"key4": 4, "key5": 5})
+	found := cache.GetMulti([]string{"key1", "key2", "key3", "key4", "key5"})
+	assert.Len(t, found, 3)
+	assert.NotContains(t, found, "key1")
+	assert.NotContains(t, found, "key2")
+	assert.Equal(t, uint64(2), cache.Stats().Evictions)
+}
+
+func TestInMemoryCache_LoadMulti(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](4, 5*time.Minute)
+	cache.Put("key1", 1)
+
+	var requested [][]string
+	loader := func(missing []string) (map[string]int, error) {
+		requested = append(requested, missing)
+		loaded := make(map[string]int)
+		for _, key := range missing {
+			if key != "unknown" {
+				loaded[key] = len(key)
+			}
+		}
+		return loaded, nil
+	}
+
+	found, err := cache.LoadMulti([]string{"key1", "key2", "unknown", "k3"}, loader)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"key1": 1, "key2": 4, "k3": 2}, found)
+	assert.Equal(t, [][]string{{"key2", "unknown", "k3"}}, requested)
+
+	// Loaded values are cached
+	found, err = cache.LoadMulti([]string{"key1", "key2", "k3"}, loader)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"key1": 1, "key2": 4, "k3": 2}, found)
+	assert.Len(t, requested, 1)
+
+	// Loader errors
+	found, err = cache.LoadMulti([]string{"key1", "key4"}, func(missing []string) (map[string]int, error) {
+		return nil, errors.New("loader error")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, map[string]int{"key1": 1}, found)
+}
+
+func TestInMemoryCache_LoadMultiLikeLoad(t *testing.T) {
+	store := newMapStore[string, int]()
+	store.data["stored"] = 10
+	cache := ugulru.NewInMemoryCache(4, 5*time.Minute,
+		ugulru.WithWriteThrough[string, int](store),
+		ugulru.WithNegativeCaching[string, int](func(err error) bool {
+			return errors.Is(err, errGone)
+		}, time.Minute))
+
+	var requested [][]string
+	found, err := cache.LoadMulti([]string{"stored", "loaded", "loaded"}, func(missing []string) (map[string]int, error) {
+		requested = append(requested, missing)
+		return map[string]int{"loaded": 1, "extra": 2}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"stored": 10, "loaded": 1}, found)
+	assert.Equal(t, [][]string{{"loaded"}}, requested, "the store should be read before the loader")
+	_, ok := cache.Get("extra")
+	assert.False(t, ok, "keys that were not requested should not be stored")
+
+	// Negative results are cached per key
+	found, err = cache.LoadMulti([]string{"gone"}, func([]string) (map[string]int, error) {
+		return nil, errGone
+	})
+	assert.ErrorIs(t, err, errGone)
+	assert.Empty(t, found)
+	found, err = cache.LoadMulti([]string{"gone"}, func([]string) (map[string]int, error) {
+		t.Fatal("the negative result should be cached")
+		return nil, nil
+	})
+	assert.ErrorIs(t, err, errGone)
+	assert.Empty(t, found)
+}
+
+func TestInMemoryCache_RemoveMulti(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+	cache.Put("key3", 3)
+
+	assert.Equal(t, 2, cache.RemoveMulti("key1", "key2", "key4"))
+	assert.Equal(t, map[string]int{"key3": 3}, cache.GetMulti([]string{"key1", "key2", "key3"}))
+	assert.Equal(t, 0, cache.RemoveMulti())
+}
+
+func TestInMemoryCache_RemoveIf(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](4, 5*time.Minute)
+	cache.Put("tenant1:a", 1)
+	cache.Put("tenant2:a", 2)
+	cache.Put("tenant1:b", 3)
+	cache.Put("tenant2:b", 4)
+
+	removed := cache.RemoveIf(func(key string, value int) bool {
+		return strings.HasPrefix(key, "tenant1:")
+	})
+	assert.Equal(t, 2, removed)
+	assert.Equal(t, []string{"tenant2:b", "tenant2:a"}, slices.Collect(cache.Keys()))
+
+	removed = cache.RemoveIf(func(key string, value int) bool {
+		return value > 10
+	})
+	assert.Equal(t, 0, removed)
+}
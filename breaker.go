@@ -0,0 +1,117 @@
+package ugulru
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by the loading methods instead of calling the loader while the circuit breaker set with
+// WithCircuitBreaker is open.
+var ErrCircuitOpen = errors.New("ugulru: circuit breaker is open")
+
+// WithCircuitBreaker protects a struggling backend from a miss storm. After threshold consecutive loader failures,
+// further loads fail with ErrCircuitOpen without calling the loader for the cooldown duration. Once the cooldown has
+// passed, the breaker is half-open: a single load is let through as a probe while the others keep failing with
+// ErrCircuitOpen, and if the probe succeeds, the breaker closes, otherwise it opens for another cooldown. If perKey is
+// true, failures are counted and the breaker opens for every key separately; the state of a key is forgotten once it
+// has had no failure for twice the cooldown, so it doesn't grow with the number of keys. Errors recognized as negative
+// results by WithNegativeCaching don't count as failures.
+func WithCircuitBreaker[K comparable, V any](threshold int, cooldown time.Duration, perKey bool) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.breaker = &breaker[K]{
+			threshold: threshold,
+			cooldown:  cooldown,
+			perKey:    perKey,
+			states:    make(map[K]*breakerState),
+		}
+	}
+}
+
+// breaker is a circuit breaker guarding loader calls.
+type breaker[K comparable] struct {
+	threshold int
+	cooldown  time.Duration
+	perKey    bool
+
+	mu      sync.Mutex
+	state   breakerState
+	states  map[K]*breakerState
+	sweepAt int
+}
+
+type breakerState struct {
+	failures    int
+	lastFailure time.Time
+	openUntil   time.Time
+	probing     bool
+}
+
+// allow reports whether a loader call for the given key may proceed. Once the cooldown has passed, only the first call
+// is allowed, as a probe, until its outcome is recorded.
+func (b *breaker[K]) allow(key K) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.lookup(key, false)
+	if state == nil || state.failures < b.threshold {
+		return true
+	}
+	if time.Now().Before(state.openUntil) || state.probing {
+		return false
+	}
+	state.probing = true
+	return true
+}
+
+// record records the outcome of a loader call for the given key.
+func (b *breaker[K]) record(key K, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if success {
+		if b.perKey {
+			delete(b.states, key)
+		} else {
+			b.state = breakerState{}
+		}
+		return
+	}
+
+	state := b.lookup(key, true)
+	state.failures++
+	state.lastFailure = time.Now()
+	state.probing = false
+	if state.failures >= b.threshold {
+		state.openUntil = state.lastFailure.Add(b.cooldown)
+	}
+}
+
+// lookup returns the state of the breaker for the given key. If create is false and the key has no state, it returns
+// nil.
+func (b *breaker[K]) lookup(key K, create bool) *breakerState {
+	if !b.perKey {
+		return &b.state
+	}
+	state, ok := b.states[key]
+	if !ok && create {
+		if len(b.states) >= b.sweepAt {
+			b.sweep()
+		}
+		state = &breakerState{}
+		b.states[key] = state
+	}
+	return state
+}
+
+// sweep forgets the states of the keys that have had no failure for twice the cooldown and are not being probed. It
+// runs again once the number of states has doubled, so its cost is amortized over the failures.
+func (b *breaker[K]) sweep() {
+	now := time.Now()
+	for key, state := range b.states {
+		if !state.probing && now.Sub(state.lastFailure) > 2*b.cooldown {
+			delete(b.states, key)
+		}
+	}
+	b.sweepAt = max(2*len(b.states), 64)
+}
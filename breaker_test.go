@@ -0,0 +1,121 @@
+package ugulru_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCircuitBreaker(t *testing.T) {
+	errUnavailable := errors.New("unavailable")
+	calls := 0
+	failing := func() (int, error) {
+		calls++
+		return 0, errUnavailable
+	}
+	working := func() (int, error) {
+		calls++
+		return 1, nil
+	}
+
+	t.Run("Test cache-wide breaker", func(t *testing.T) {
+		calls = 0
+		cache := ugulru.NewInMemoryCache(2, 5*time.Minute, ugulru.WithCircuitBreaker[string, int](2, 100*time.Millisecond, false))
+
+		_, err := cache.Load("key1", failing)
+		assert.ErrorIs(t, err, errUnavailable)
+		_, err = cache.Load("key2", failing)
+		assert.ErrorIs(t, err, errUnavailable)
+
+		// The breaker is open for all keys
+		_, err = cache.Load("key3", working)
+		assert.ErrorIs(t, err, ugulru.ErrCircuitOpen)
+		assert.Equal(t, 2, calls)
+
+		// After the cooldown a failing call opens the breaker again
+		time.Sleep(150 * time.Millisecond)
+		_, err = cache.Load("key3", failing)
+		assert.ErrorIs(t, err, errUnavailable)
+		_, err = cache.Load("key3", working)
+		assert.ErrorIs(t, err, ugulru.ErrCircuitOpen)
+
+		// After the cooldown a successful call closes the breaker
+		time.Sleep(150 * time.Millisecond)
+		value, err := cache.Load("key3", working)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, value)
+		_, err = cache.Load("key4", failing)
+		assert.ErrorIs(t, err, errUnavailable)
+	})
+
+	t.Run("Test per-key breaker", func(t *testing.T) {
+		calls = 0
+		cache := ugulru.NewInMemoryCache(2, 5*time.Minute, ugulru.WithCircuitBreaker[string, int](1, time.Minute, true))
+
+		_, err := cache.Load("key1", failing)
+		assert.ErrorIs(t, err, errUnavailable)
+		_, err = cache.Load("key1", working)
+		assert.ErrorIs(t, err, ugulru.ErrCircuitOpen)
+
+		// Other keys are not affected
+		value, err := cache.Load("key2", working)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, value)
+	})
+}
+
+func TestWithCircuitBreaker_HalfOpen(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(10, 5*time.Minute, ugulru.WithCircuitBreaker[string, int](1, 50*time.Millisecond, false))
+	_, err := cache.Load("key1", func() (int, error) {
+		return 0, errors.New("unavailable")
+	})
+	assert.Error(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	// Only one probe is let through while the breaker is half-open
+	probing := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := cache.LoadContext(context.Background(), "key2", func(context.Context) (int, error) {
+			close(probing)
+			<-release
+			return 2, nil
+		})
+		done <- err
+	}()
+	<-probing
+	_, err = cache.LoadContext(context.Background(), "key3", func(context.Context) (int, error) {
+		return 3, nil
+	})
+	assert.ErrorIs(t, err, ugulru.ErrCircuitOpen)
+	close(release)
+	assert.NoError(t, <-done)
+
+	// The successful probe closed the breaker
+	value, err := cache.Load("key3", func() (int, error) {
+		return 3, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, value)
+}
+
+func TestWithCircuitBreaker_PerKeyBounded(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(10, 5*time.Minute, ugulru.WithCircuitBreaker[string, int](3, 10*time.Millisecond, true))
+	failing := func() (int, error) {
+		return 0, errors.New("unavailable")
+	}
+	for i := range 100 {
+		cache.Load(fmt.Sprint("old", i), failing)
+	}
+	time.Sleep(50 * time.Millisecond)
+	for i := range 100 {
+		cache.Load(fmt.Sprint("new", i), failing)
+	}
+	assert.LessOrEqual(t, cache.BreakerKeys(), 128, "the states of idle keys should be forgotten")
+}
@@ -0,0 +1,97 @@
+package ugulru
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// LoaderStage is a single stage of a loader chain, such as a local replica, a regional service or the origin.
+type LoaderStage[K comparable, V any] struct {
+	// Name identifies the stage. It is recorded as the source of the entries loaded by the stage.
+	Name string
+	// Load loads the value for the given key.
+	Load func(ctx context.Context, key K) (V, error)
+	// Timeout bounds the execution time of Load. Zero means no timeout.
+	Timeout time.Duration
+	// Fatal reports whether an error returned by Load must abort the chain instead of falling through to the next
+	// stage, e.g. when the key is known not to exist. If Fatal is nil, all errors fall through.
+	Fatal func(err error) bool
+}
+
+// WithLoaderChain registers an ordered chain of loader stages used by LoadChain.
+func WithLoaderChain[K comparable, V any](stages ...LoaderStage[K, V]) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.chain = stages
+	}
+}
+
+// LoadChain retrieves the value from the cache based on the given key. If the key doesn't exist in the cache or has
+// expired, the stages registered with WithLoaderChain are tried in order and the value returned by the first
+// successful stage is stored in the cache, tagged with the name of the stage.
+//
+// The cache is not locked while the stages are running. If all stages fail, or a stage fails with a fatal error, the
+// returned error joins the errors of all the stages that were tried, each prefixed with the name of its stage.
+func (c *InMemoryCache[K, V]) LoadChain(ctx context.Context, key K) (V, error) {
+	c.mu.Lock()
+	cached, ok := c.get(key)
+	var value V
+	if ok {
+		value = cached.value
+	}
+	c.mu.Unlock()
+	if ok {
+		return value, nil
+	}
+
+	var errs []error
+	start := time.Now()
+	for _, stage := range c.chain {
+		value, err := runStage(ctx, stage, key)
+		if err == nil {
+			c.mu.Lock()
+			penalty := time.Since(start)
+			c.store(key, value, func(e *entry[K, V]) {
+				e.source = stage.Name
+				e.penalty = penalty
+			})
+			c.mu.Unlock()
+			return value, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", stage.Name, err))
+		if (stage.Fatal != nil && stage.Fatal(err)) || ctx.Err() != nil {
+			break
+		}
+	}
+
+	var zero V
+	if len(errs) == 0 {
+		return zero, errors.New("ugulru: no loader stages registered")
+	}
+	return zero, errors.Join(errs...)
+}
+
+// GetWithSource works like Get but also returns the name of the loader stage the value was loaded from by LoadChain.
+// The source is empty for values that were stored in any other way.
+func (c *InMemoryCache[K, V]) GetWithSource(key K) (V, string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.get(key); ok {
+		return entry.value, entry.source, true
+	}
+	var zero V
+	return zero, "", false
+}
+
+func runStage[K comparable, V any](ctx context.Context, stage LoaderStage[K, V], key K) (V, error) {
+	if stage.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, stage.Timeout)
+		defer cancel()
+	}
+	return protect(ctx, func(ctx context.Context) (V, error) {
+		return stage.Load(ctx, key)
+	})
+}
@@ -0,0 +1,90 @@
+package ugulru_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryCache_LoadChain(t *testing.T) {
+	errUnavailable := errors.New("unavailable")
+	errNotFound := errors.New("not found")
+
+	failing := ugulru.LoaderStage[string, int]{
+		Name: "replica",
+		Load: func(ctx context.Context, key string) (int, error) {
+			return 0, errUnavailable
+		},
+	}
+	slow := ugulru.LoaderStage[string, int]{
+		Name: "regional",
+		Load: func(ctx context.Context, key string) (int, error) {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		},
+		Timeout: 10 * time.Millisecond,
+	}
+	origin := ugulru.LoaderStage[string, int]{
+		Name: "origin",
+		Load: func(ctx context.Context, key string) (int, error) {
+			if key == "missing" {
+				return 0, errNotFound
+			}
+			return len(key), nil
+		},
+		Fatal: func(err error) bool {
+			return errors.Is(err, errNotFound)
+		},
+	}
+
+	t.Run("Test falling through to a successful stage", func(t *testing.T) {
+		cache := ugulru.NewInMemoryCache(2, 5*time.Minute, ugulru.WithLoaderChain(failing, slow, origin))
+		value, err := cache.LoadChain(context.Background(), "key1")
+		assert.NoError(t, err)
+		assert.Equal(t, 4, value)
+
+		value, source, ok := cache.GetWithSource("key1")
+		assert.True(t, ok)
+		assert.Equal(t, 4, value)
+		assert.Equal(t, "origin", source)
+
+		// Values stored with Put have no source
+		cache.Put("key1", 1)
+		_, source, _ = cache.GetWithSource("key1")
+		assert.Equal(t, "", source)
+	})
+
+	t.Run("Test all stages failing", func(t *testing.T) {
+		cache := ugulru.NewInMemoryCache(2, 5*time.Minute, ugulru.WithLoaderChain(failing, slow))
+		_, err := cache.LoadChain(context.Background(), "key1")
+		assert.ErrorIs(t, err, errUnavailable)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		_, ok := cache.Get("key1")
+		assert.False(t, ok)
+	})
+
+	t.Run("Test fatal error aborting the chain", func(t *testing.T) {
+		called := false
+		last := ugulru.LoaderStage[string, int]{
+			Name: "last",
+			Load: func(ctx context.Context, key string) (int, error) {
+				called = true
+				return 0, nil
+			},
+		}
+		cache := ugulru.NewInMemoryCache(2, 5*time.Minute, ugulru.WithLoaderChain(origin, last))
+		_, err := cache.LoadChain(context.Background(), "missing")
+		assert.ErrorIs(t, err, errNotFound)
+		assert.False(t, called)
+	})
+
+	t.Run("Test no stages", func(t *testing.T) {
+		cache := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+		_, err := cache.LoadChain(context.Background(), "key1")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,170 @@
+package ugulru
+
+import (
+	"sync"
+	"time"
+)
+
+// ClockCache is a cache with a fixed capacity and a TTL that uses the CLOCK approximation of LRU. Entries live in a
+// circular buffer with a reference bit that is set on every hit; to make room, a clock hand sweeps the buffer, clearing
+// set bits and evicting the first entry whose bit is already clear. Hits don't touch any list, which makes them cheaper
+// and more cache friendly than in InMemoryCache, at the cost of a slightly lower hit ratio.
+type ClockCache[K comparable, V any] struct {
+	mu    sync.Mutex
+	index map[K]int
+	slots []clockSlot[K, V]
+	free  []int
+	hand  int
+	ttl   time.Duration
+	epoch time.Time
+}
+
+var _ Cache[string, any] = (*ClockCache[string, any])(nil)
+
+type clockSlot[K comparable, V any] struct {
+	key     K
+	value   V
+	expires int64 // nanoseconds since the epoch of the cache
+	used    bool
+	ref     bool
+}
+
+// NewClockCache creates a new CLOCK cache with the specified capacity and TTL duration. It panics if capacity is not
+// positive.
+func NewClockCache[K comparable, V any](capacity int, ttl time.Duration) *ClockCache[K, V] {
+	if capacity <= 0 {
+		panic("ugulru: capacity must be positive")
+	}
+	c := &ClockCache[K, V]{
+		index: make(map[K]int, capacity),
+		slots: make([]clockSlot[K, V], capacity),
+		free:  make([]int, capacity),
+		ttl:   ttl,
+		epoch: time.Now(),
+	}
+	for i := range c.free {
+		c.free[i] = capacity - 1 - i
+	}
+	return c
+}
+
+// Get retrieves a value from the cache based on the given key. It returns the value and a boolean indicating whether
+// the key exists in the cache.
+func (c *ClockCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.get(key)
+}
+
+// Put adds or updates a key-value pair in the cache.
+func (c *ClockCache[K, V]) Put(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.put(key, value)
+}
+
+// Remove deletes the key from the cache.
+func (c *ClockCache[K, V]) Remove(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if i, ok := c.index[key]; ok {
+		c.remove(i)
+	}
+}
+
+// RemoveExpired removes all expired entries from the cache.
+func (c *ClockCache[K, V]) RemoveExpired() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for i := range c.slots {
+		if c.slots[i].used && now > c.slots[i].expires {
+			c.remove(i)
+		}
+	}
+}
+
+// Load retrieves the value from the cache based on the given key, calling the loader to load and store the value if
+// the key doesn't exist in the cache or has expired.
+func (c *ClockCache[K, V]) Load(key K, loader func() (V, error)) (V, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if value, ok := c.get(key); ok {
+		return value, nil
+	}
+	value, err := loader()
+	if err != nil {
+		return value, err
+	}
+	c.put(key, value)
+	return value, nil
+}
+
+// Len returns the number of entries in the cache, including expired ones that have not been removed yet.
+func (c *ClockCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.index)
+}
+
+// get returns the live value of the given key and sets its reference bit. It must be called with the lock held.
+func (c *ClockCache[K, V]) get(key K) (V, bool) {
+	i, ok := c.index[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	slot := &c.slots[i]
+	if c.now() > slot.expires {
+		c.remove(i)
+		var zero V
+		return zero, false
+	}
+	slot.ref = true
+	return slot.value, true
+}
+
+// put inserts or updates the value associated with the given key. It must be called with the lock held.
+func (c *ClockCache[K, V]) put(key K, value V) {
+	i, ok := c.index[key]
+	if !ok {
+		if len(c.free) == 0 {
+			c.remove(c.sweep())
+		}
+		i = c.free[len(c.free)-1]
+		c.free = c.free[:len(c.free)-1]
+		c.index[key] = i
+	}
+	c.slots[i] = clockSlot[K, V]{key: key, value: value, expires: c.now() + int64(c.ttl), used: true}
+}
+
+// sweep advances the clock hand to the first slot whose reference bit is clear, clearing the set bits on its way, and
+// returns that slot. It must be called with the lock held and a full buffer.
+func (c *ClockCache[K, V]) sweep() int {
+	for {
+		i := c.hand
+		c.hand = (c.hand + 1) % len(c.slots)
+		if !c.slots[i].ref {
+			return i
+		}
+		c.slots[i].ref = false
+	}
+}
+
+// remove frees the given slot. It must be called with the lock held.
+func (c *ClockCache[K, V]) remove(i int) {
+	delete(c.index, c.slots[i].key)
+	c.slots[i] = clockSlot[K, V]{}
+	c.free = append(c.free, i)
+}
+
+// now returns the current time in nanoseconds since the epoch of the cache.
+func (c *ClockCache[K, V]) now() int64 {
+	return int64(time.Since(c.epoch))
+}
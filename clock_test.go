@@ -0,0 +1,61 @@
+package ugulru_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClockCache(t *testing.T) {
+	cache := ugulru.NewClockCache[string, int](3, 100*time.Millisecond)
+
+	cache.Put("key1", 1)
+	value, ok := cache.Get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	cache.Put("key1", 2)
+	value, _ = cache.Get("key1")
+	assert.Equal(t, 2, value)
+
+	cache.Remove("key1")
+	_, ok = cache.Get("key1")
+	assert.False(t, ok)
+
+	value, err := cache.Load("key2", func() (int, error) { return 3, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 3, value)
+	_, err = cache.Load("key3", func() (int, error) { return 0, errors.New("loader error") })
+	assert.Error(t, err)
+
+	time.Sleep(150 * time.Millisecond)
+	_, ok = cache.Get("key2")
+	assert.False(t, ok)
+
+	cache.Put("key4", 4)
+	time.Sleep(150 * time.Millisecond)
+	cache.RemoveExpired()
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestClockCache_Eviction(t *testing.T) {
+	cache := ugulru.NewClockCache[string, int](3, 5*time.Minute)
+
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+	cache.Put("key3", 3)
+	cache.Get("key1")
+	cache.Get("key3")
+
+	cache.Put("key4", 4) // evicts key2, the only entry without the reference bit
+	assert.Equal(t, 3, cache.Len())
+	_, ok := cache.Get("key2")
+	assert.False(t, ok)
+	for _, key := range []string{"key1", "key3", "key4"} {
+		_, ok := cache.Get(key)
+		assert.True(t, ok, key)
+	}
+}
@@ -0,0 +1,198 @@
+// Command ugulru-gen generates a specialized, non-generic LRU cache with a TTL for a given key and value type, for the
+// few very hot caches where the overhead of generics and of the extra features of ugulru.InMemoryCache matters. It is
+// meant to be used with go:generate:
+//
+//	//go:generate go run github.com/machine23/ugulru/cmd/ugulru-gen -name UserCache -key int64 -value *User
+//
+// The generated cache supports Get, Put, Remove and Len, and is safe for concurrent use.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+func main() {
+	var p params
+	var output, imports string
+	flag.StringVar(&p.Name, "name", "", "name of the generated cache type")
+	flag.StringVar(&p.Key, "key", "", "key type")
+	flag.StringVar(&p.Value, "value", "", "value type")
+	flag.StringVar(&p.Package, "package", os.Getenv("GOPACKAGE"), "package of the generated file")
+	flag.StringVar(&imports, "imports", "", "comma-separated list of packages the key and value types need")
+	flag.StringVar(&output, "o", "", "output file (default: <name>_ugulru.go)")
+	flag.Parse()
+
+	if p.Name == "" || p.Key == "" || p.Value == "" || p.Package == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if imports != "" {
+		p.Imports = strings.Split(imports, ",")
+	}
+	if output == "" {
+		output = strings.ToLower(p.Name) + "_ugulru.go"
+	}
+
+	src, err := generate(p)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ugulru-gen:", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(output, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "ugulru-gen:", err)
+		os.Exit(1)
+	}
+}
+
+// params are the parameters of the generated cache.
+type params struct {
+	Package string
+	Imports []string
+	Name    string
+	Key     string
+	Value   string
+}
+
+// EntryName returns the name of the unexported entry type of the generated cache.
+func (p params) EntryName() string {
+	r, size := utf8.DecodeRuneInString(p.Name)
+	return string(unicode.ToLower(r)) + p.Name[size:] + "Entry"
+}
+
+// generate returns the formatted source of the cache described by the given parameters.
+func generate(p params) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, p); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+var tmpl = template.Must(template.New("cache").Parse(`// Code generated by ugulru-gen; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"sync"
+	"time"
+	{{range .Imports}}
+	"{{.}}"
+	{{- end}}
+)
+
+// {{.Name}} is an LRU cache of {{.Value}} values by {{.Key}} keys with a fixed capacity and a TTL.
+type {{.Name}} struct {
+	mu       sync.Mutex
+	items    map[{{.Key}}]*{{.EntryName}}
+	head     {{.EntryName}} // sentinel of the circular list, head.next is the most recently used entry
+	capacity int
+	ttl      int64
+	epoch    time.Time
+}
+
+type {{.EntryName}} struct {
+	key        {{.Key}}
+	value      {{.Value}}
+	expires    int64
+	prev, next *{{.EntryName}}
+}
+
+// New{{.Name}} creates a new cache with the given capacity and TTL.
+func New{{.Name}}(capacity int, ttl time.Duration) *{{.Name}} {
+	if capacity <= 0 {
+		panic("capacity must be positive")
+	}
+	c := &{{.Name}}{
+		items:    make(map[{{.Key}}]*{{.EntryName}}, capacity),
+		capacity: capacity,
+		ttl:      int64(ttl),
+		epoch:    time.Now(),
+	}
+	c.head.prev, c.head.next = &c.head, &c.head
+	return c
+}
+
+// Get retrieves the value for the key if it exists and has not expired.
+func (c *{{.Name}}) Get(key {{.Key}}) ({{.Value}}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		var zero {{.Value}}
+		return zero, false
+	}
+	if c.now() > e.expires {
+		c.unlink(e)
+		delete(c.items, key)
+		var zero {{.Value}}
+		return zero, false
+	}
+	c.unlink(e)
+	c.pushFront(e)
+	return e.value, true
+}
+
+// Put stores the value for the key, evicting the least recently used entry if the cache is full.
+func (c *{{.Name}}) Put(key {{.Key}}, value {{.Value}}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		e.value = value
+		e.expires = c.now() + c.ttl
+		c.unlink(e)
+		c.pushFront(e)
+		return
+	}
+	if len(c.items) >= c.capacity {
+		victim := c.head.prev
+		c.unlink(victim)
+		delete(c.items, victim.key)
+	}
+	e := &{{.EntryName}}{key: key, value: value, expires: c.now() + c.ttl}
+	c.items[key] = e
+	c.pushFront(e)
+}
+
+// Remove deletes the key from the cache.
+func (c *{{.Name}}) Remove(key {{.Key}}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.unlink(e)
+		delete(c.items, key)
+	}
+}
+
+// Len returns the number of entries in the cache, including expired ones that have not been removed yet.
+func (c *{{.Name}}) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.items)
+}
+
+func (c *{{.Name}}) now() int64 {
+	return int64(time.Since(c.epoch))
+}
+
+func (c *{{.Name}}) unlink(e *{{.EntryName}}) {
+	e.prev.next, e.next.prev = e.next, e.prev
+}
+
+func (c *{{.Name}}) pushFront(e *{{.EntryName}}) {
+	e.prev, e.next = &c.head, c.head.next
+	c.head.next.prev = e
+	c.head.next = e
+}
+`))
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	src, err := generate(params{Package: "users", Imports: []string{"net/netip"}, Name: "AddrCache", Key: "string", Value: "netip.Addr"})
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "func NewAddrCache(capacity int, ttl time.Duration) *AddrCache")
+	assert.Contains(t, string(src), "items    map[string]*addrCacheEntry")
+}
+
+func TestGenerate_Compiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a generated package")
+	}
+	src, err := generate(params{Package: "main", Name: "Cache", Key: "int", Value: "string"})
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	prog := `package main
+
+import (
+	"fmt"
+	"time"
+)
+
+func main() {
+	c := NewCache(2, time.Minute)
+	c.Put(1, "a")
+	c.Put(2, "b")
+	c.Get(1)
+	c.Put(3, "c")
+	_, ok2 := c.Get(2)
+	v1, ok1 := c.Get(1)
+	c.Remove(3)
+	fmt.Println(v1, ok1, ok2, c.Len())
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module gen\n\ngo 1.23\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cache.go"), src, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(prog), 0o644))
+
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.Equal(t, "a true false 1\n", string(out))
+}
@@ -0,0 +1,67 @@
+// Command ugulru-sim replays an access trace against the eviction policies of ugulru with different capacities and
+// reports the hit ratios, evictions and key memory of each combination:
+//
+//	ugulru-sim -policies lru,tinylfu,s3fifo -capacities 1000,10000 trace.txt
+//
+// The trace has one access per line: either a bare key, or a key and a timestamp separated by a comma. It is read from
+// the standard input if no file is given.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/machine23/ugulru/sim"
+)
+
+func main() {
+	policies := flag.String("policies", strings.Join(sim.Policies, ","), "comma-separated list of policies")
+	capacities := flag.String("capacities", "1000", "comma-separated list of capacities")
+	flag.Parse()
+
+	if err := run(flag.Args(), strings.Split(*policies, ","), *capacities, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "ugulru-sim:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args, policies []string, capacities string, stdin io.Reader, stdout io.Writer) error {
+	var caps []int
+	for _, s := range strings.Split(capacities, ",") {
+		capacity, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || capacity <= 0 {
+			return fmt.Errorf("invalid capacity %q", s)
+		}
+		caps = append(caps, capacity)
+	}
+
+	in := stdin
+	if len(args) > 0 {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	trace, err := sim.ReadTrace(in)
+	if err != nil {
+		return err
+	}
+
+	results, err := sim.ReplayAll(trace, policies, caps)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "policy\tcapacity\thits\tmisses\thit ratio\tevictions\tkey memory\t")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.4f\t%d\t%d\t\n", r.Policy, r.Capacity, r.Hits, r.Misses, r.HitRatio(), r.Evictions, r.KeyMemory)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	var out bytes.Buffer
+	err := run(nil, []string{"lru", "clock"}, "1,2", strings.NewReader("a\nb\na\nb\n"), &out)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 5)
+	assert.Contains(t, lines[0], "hit ratio")
+	assert.Equal(t, []string{"lru", "2", "2", "2", "0.5000", "0", "34"}, strings.Fields(lines[2]))
+
+	assert.Error(t, run(nil, []string{"lru"}, "zero", strings.NewReader(""), &out))
+	assert.Error(t, run([]string{"missing.txt"}, []string{"lru"}, "1", strings.NewReader(""), &out))
+}
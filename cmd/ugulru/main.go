@@ -0,0 +1,357 @@
+// Command ugulru lets operators inspect cache snapshots and poke at remote caches without writing Go:
+//
+//	ugulru dump [-key-type t] [-value-type t] [-codec c] snapshot   prints the entries of a snapshot as JSON lines
+//	ugulru inspect [flags] snapshot                                 prints a summary of a snapshot
+//	ugulru sizes [flags] snapshot                                   prints histograms of the key and value sizes
+//	ugulru get (-redis addr | -memcached addrs) [-codec c] key      prints the value of a key
+//	ugulru del (-redis addr | -memcached addrs) key                 deletes a key
+//
+// Snapshots are the files written by InMemoryCache.Snapshot and WithSnapshotFile. Since snapshots don't record the
+// types of their keys and values, they are given with -key-type (string or int) and -value-type (string, int, float,
+// bool or bytes). Values of snapshots written with WithCodec, and values of remote caches, are decoded with -codec
+// (json or raw). Memcached servers are given as a comma-separated list and picked by the same consistent hashing as
+// memcachedcache.Servers, so keys are looked up on the server the cache wrote them to.
+package main
+
+import (
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/bits"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/machine23/ugulru"
+	"github.com/machine23/ugulru/memcachedcache"
+	"github.com/redis/go-redis/v9"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "ugulru:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("missing command: dump, inspect, sizes, get or del")
+	}
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "dump", "inspect", "sizes":
+		return runSnapshot(cmd, args, stdout)
+	case "get", "del":
+		return runRemote(cmd, args, stdout)
+	}
+	return fmt.Errorf("unknown command %q", cmd)
+}
+
+// record is an entry of a snapshot, with the value decoded if possible and its encoded form otherwise.
+type record struct {
+	Key     any       `json:"key"`
+	Value   any       `json:"value"`
+	Expires time.Time `json:"expires"`
+	size    int64
+}
+
+func runSnapshot(cmd string, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	keyType := flags.String("key-type", "string", "type of the keys: string or int")
+	valueType := flags.String("value-type", "string", "type of the values: string, int, float, bool or bytes")
+	codec := flags.String("codec", "raw", "codec of the values of snapshots written with a codec: json or raw")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("expected a snapshot file")
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	version, records, err := readSnapshot(f, *keyType, *valueType, *codec)
+	if err != nil {
+		return err
+	}
+
+	switch cmd {
+	case "dump":
+		enc := json.NewEncoder(stdout)
+		for _, r := range records {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+	case "inspect":
+		return inspect(stdout, version, records)
+	case "sizes":
+		return sizes(stdout, records)
+	}
+	return nil
+}
+
+// readSnapshot reads the entries of the snapshot in r with the given key and value types.
+func readSnapshot(r io.Reader, keyType, valueType, codec string) (int, []record, error) {
+	switch keyType {
+	case "string":
+		return readSnapshotKeys[string](r, valueType, codec)
+	case "int":
+		return readSnapshotKeys[int64](r, valueType, codec)
+	}
+	return 0, nil, fmt.Errorf("unsupported key type %q", keyType)
+}
+
+func readSnapshotKeys[K comparable](r io.Reader, valueType, codec string) (int, []record, error) {
+	dec := gob.NewDecoder(r)
+	var header struct{ Version, Len int }
+	if err := dec.Decode(&header); err != nil {
+		return 0, nil, fmt.Errorf("read snapshot: %w", err)
+	}
+	if header.Version == 2 {
+		records, err := readEntries[K, []byte](dec, header.Len)
+		if err != nil {
+			return 0, nil, err
+		}
+		for i := range records {
+			if records[i].Value, err = decodeValue(records[i].Value.([]byte), codec); err != nil {
+				return 0, nil, err
+			}
+		}
+		return header.Version, records, nil
+	}
+	if header.Version != 1 {
+		return 0, nil, fmt.Errorf("unsupported snapshot version %d", header.Version)
+	}
+
+	var records []record
+	var err error
+	switch valueType {
+	case "string":
+		records, err = readEntries[K, string](dec, header.Len)
+	case "int":
+		records, err = readEntries[K, int64](dec, header.Len)
+	case "float":
+		records, err = readEntries[K, float64](dec, header.Len)
+	case "bool":
+		records, err = readEntries[K, bool](dec, header.Len)
+	case "bytes":
+		records, err = readEntries[K, []byte](dec, header.Len)
+	default:
+		return 0, nil, fmt.Errorf("unsupported value type %q", valueType)
+	}
+	return header.Version, records, err
+}
+
+func readEntries[K comparable, V any](dec *gob.Decoder, n int) ([]record, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("read snapshot: invalid length %d", n)
+	}
+	var records []record
+	for range n {
+		var e struct {
+			Key     K
+			Value   V
+			Expires time.Time
+		}
+		if err := dec.Decode(&e); err != nil {
+			return nil, fmt.Errorf("read snapshot: %w", err)
+		}
+		records = append(records, record{Key: e.Key, Value: e.Value, Expires: e.Expires, size: ugulru.EstimateSize(e.Value)})
+	}
+	return records, nil
+}
+
+// decodeValue decodes the given encoded value with the given codec.
+func decodeValue(data []byte, codec string) (any, error) {
+	switch codec {
+	case "raw":
+		return string(data), nil
+	case "json":
+		return ugulru.JSONCodec[any]{}.Decode(data)
+	}
+	return nil, fmt.Errorf("unsupported codec %q", codec)
+}
+
+func inspect(w io.Writer, version int, records []record) error {
+	now := time.Now()
+	expired := 0
+	var first, last time.Time
+	for _, r := range records {
+		if !r.Expires.After(now) {
+			expired++
+		}
+		if first.IsZero() || r.Expires.Before(first) {
+			first = r.Expires
+		}
+		if r.Expires.After(last) {
+			last = r.Expires
+		}
+	}
+	fmt.Fprintf(w, "version: %d\n", version)
+	fmt.Fprintf(w, "entries: %d\n", len(records))
+	fmt.Fprintf(w, "expired: %d\n", expired)
+	if len(records) > 0 {
+		fmt.Fprintf(w, "expires: %s to %s\n", first.Format(time.RFC3339), last.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func sizes(w io.Writer, records []record) error {
+	keys := make([]int64, len(records))
+	values := make([]int64, len(records))
+	for i, r := range records {
+		keys[i] = ugulru.EstimateSize(r.Key)
+		values[i] = r.size
+		if values[i] == 0 {
+			values[i] = ugulru.EstimateSize(r.Value)
+		}
+	}
+	fmt.Fprintln(w, "keys:")
+	histogram(w, keys)
+	fmt.Fprintln(w, "values:")
+	histogram(w, values)
+	return nil
+}
+
+// histogram prints the number of the given sizes in power-of-two buckets.
+func histogram(w io.Writer, sizes []int64) {
+	var buckets [64]int
+	top := 0
+	for _, size := range sizes {
+		b := bits.Len64(uint64(max(size-1, 0)))
+		buckets[b]++
+		top = max(top, b)
+	}
+	if len(sizes) == 0 {
+		return
+	}
+	for b := range top + 1 {
+		fmt.Fprintf(w, "  <= %d B\t%d\n", int64(1)<<b, buckets[b])
+	}
+}
+
+func runRemote(cmd string, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	redisAddr := flags.String("redis", "", "address of the Redis server")
+	memcachedAddrs := flags.String("memcached", "", "comma-separated addresses of the memcached servers")
+	codec := flags.String("codec", "raw", "codec of the values: json or raw")
+	timeout := flags.Duration("timeout", 5*time.Second, "timeout of the command")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("expected a key")
+	}
+	key := flags.Arg(0)
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	var r remote
+	switch {
+	case *redisAddr != "" && *memcachedAddrs == "":
+		r = newRedisRemote(*redisAddr)
+	case *memcachedAddrs != "" && *redisAddr == "":
+		var err error
+		if r, err = newMemcachedRemote(strings.Split(*memcachedAddrs, ","), *timeout); err != nil {
+			return err
+		}
+	default:
+		return errors.New("expected either -redis or -memcached")
+	}
+	defer r.Close()
+
+	if cmd == "del" {
+		return r.Delete(ctx, key)
+	}
+	data, err := r.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	value, err := decodeValue(data, *codec)
+	if err != nil {
+		return err
+	}
+	if s, ok := value.(string); ok {
+		_, err = fmt.Fprintln(stdout, s)
+		return err
+	}
+	return json.NewEncoder(stdout).Encode(value)
+}
+
+// remote is a remote cache server holding encoded values.
+type remote interface {
+	// Get returns the value of the given key, or ugulru.ErrNotFound if there is none.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete deletes the given key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	Close() error
+}
+
+type redisRemote struct {
+	client *redis.Client
+}
+
+func newRedisRemote(addr string) *redisRemote {
+	return &redisRemote{client: redis.NewClient(&redis.Options{Addr: addr})}
+}
+
+func (r *redisRemote) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := r.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ugulru.ErrNotFound
+	}
+	return data, err
+}
+
+func (r *redisRemote) Delete(ctx context.Context, key string) error {
+	return r.client.Del(ctx, key).Err()
+}
+
+func (r *redisRemote) Close() error {
+	return r.client.Close()
+}
+
+type memcachedRemote struct {
+	client *memcache.Client
+}
+
+func newMemcachedRemote(addrs []string, timeout time.Duration) (*memcachedRemote, error) {
+	servers, err := memcachedcache.NewServers(addrs...)
+	if err != nil {
+		return nil, err
+	}
+	client := memcache.NewFromSelector(servers)
+	client.Timeout = timeout
+	return &memcachedRemote{client: client}, nil
+}
+
+func (m *memcachedRemote) Get(_ context.Context, key string) ([]byte, error) {
+	item, err := m.client.Get(key)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return nil, ugulru.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item.Value, nil
+}
+
+func (m *memcachedRemote) Delete(_ context.Context, key string) error {
+	if err := m.client.Delete(key); err != nil && !errors.Is(err, memcache.ErrCacheMiss) {
+		return err
+	}
+	return nil
+}
+
+func (m *memcachedRemote) Close() error {
+	return m.client.Close()
+}
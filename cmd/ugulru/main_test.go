@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSnapshot[K comparable, V any](t *testing.T, cache *ugulru.InMemoryCache[K, V]) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	require.NoError(t, cache.SnapshotFile(path))
+	return path
+}
+
+func TestRun_Snapshot(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[int, string](10, time.Hour)
+	cache.Put(1, "one")
+	cache.Put(2, strings.Repeat("x", 100))
+	path := writeSnapshot(t, cache)
+
+	var out bytes.Buffer
+	require.NoError(t, run([]string{"dump", "-key-type", "int", path}, &out))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"key":2`)
+	assert.Contains(t, lines[1], `"key":1,"value":"one"`)
+
+	out.Reset()
+	require.NoError(t, run([]string{"inspect", "-key-type", "int", path}, &out))
+	assert.Contains(t, out.String(), "version: 1\nentries: 2\nexpired: 0\n")
+
+	out.Reset()
+	require.NoError(t, run([]string{"sizes", "-key-type", "int", path}, &out))
+	assert.Contains(t, out.String(), "keys:\n  <= 1 B\t0\n  <= 2 B\t0\n  <= 4 B\t0\n  <= 8 B\t2\nvalues:\n")
+	assert.Contains(t, out.String(), "<= 128 B\t1\n")
+
+	assert.Error(t, run([]string{"dump", path}, &out), "the key type should match the snapshot")
+	assert.Error(t, run([]string{"dump", "-value-type", "complex", path}, &out))
+	assert.Error(t, run([]string{"dump", filepath.Join(t.TempDir(), "missing")}, &out))
+}
+
+func TestRun_SnapshotWithCodec(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(10, time.Hour, ugulru.WithCodec[string, map[string]int](ugulru.JSONCodec[map[string]int]{}))
+	cache.Put("key1", map[string]int{"a": 1})
+	path := writeSnapshot(t, cache)
+
+	var out bytes.Buffer
+	require.NoError(t, run([]string{"dump", "-codec", "json", path}, &out))
+	assert.Contains(t, out.String(), `"key":"key1","value":{"a":1}`)
+}
+
+func TestRun_Remote(t *testing.T) {
+	server := miniredis.RunT(t)
+	require.NoError(t, server.Set("users:1", `{"name":"alice"}`))
+
+	var out bytes.Buffer
+	require.NoError(t, run([]string{"get", "-redis", server.Addr(), "-codec", "json", "users:1"}, &out))
+	assert.Equal(t, `{"name":"alice"}`+"\n", out.String())
+
+	out.Reset()
+	require.NoError(t, run([]string{"get", "-redis", server.Addr(), "users:1"}, &out))
+	assert.Equal(t, `{"name":"alice"}`+"\n", out.String())
+
+	require.NoError(t, run([]string{"del", "-redis", server.Addr(), "users:1"}, &out))
+	assert.False(t, server.Exists("users:1"))
+	assert.ErrorIs(t, run([]string{"get", "-redis", server.Addr(), "users:1"}, &out), ugulru.ErrNotFound)
+
+	assert.Error(t, run([]string{"get", "users:1"}, &out))
+	assert.Error(t, run([]string{"unknown"}, &out))
+	assert.Error(t, run(nil, &out))
+}
+
+func TestRun_SnapshotCorruptLength(t *testing.T) {
+	for _, length := range []int{-1, math.MaxInt} {
+		var buf bytes.Buffer
+		require.NoError(t, gob.NewEncoder(&buf).Encode(struct{ Version, Len int }{1, length}))
+		path := filepath.Join(t.TempDir(), "cache.snapshot")
+		require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+		var out bytes.Buffer
+		assert.Error(t, run([]string{"dump", path}, &out))
+	}
+}
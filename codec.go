@@ -0,0 +1,73 @@
+package ugulru
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+)
+
+// Codec converts values to and from bytes for persistence and remote tiers, such as snapshots, Redis or memcached.
+type Codec[V any] interface {
+	Encode(value V) ([]byte, error)
+	Decode(data []byte) (V, error)
+}
+
+// GobCodec is a Codec that encodes values with encoding/gob. Each value is encoded on its own, including the type
+// information, so it is best suited for values that are large compared to their type.
+type GobCodec[V any] struct{}
+
+func (GobCodec[V]) Encode(value V) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&value); err != nil {
+		return nil, fmt.Errorf("ugulru: encode value: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (GobCodec[V]) Decode(data []byte) (V, error) {
+	var value V
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value); err != nil {
+		return value, fmt.Errorf("ugulru: decode value: %w", err)
+	}
+	return value, nil
+}
+
+// JSONCodec is a Codec that encodes values as JSON, so they can be read by services written in other languages.
+type JSONCodec[V any] struct{}
+
+func (JSONCodec[V]) Encode(value V) ([]byte, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("ugulru: encode value: %w", err)
+	}
+	return b, nil
+}
+
+func (JSONCodec[V]) Decode(data []byte) (V, error) {
+	var value V
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("ugulru: decode value: %w", err)
+	}
+	return value, nil
+}
+
+// BytesCodec is a Codec for values that are already raw bytes, which are used as is.
+type BytesCodec[V ~[]byte] struct{}
+
+func (BytesCodec[V]) Encode(value V) ([]byte, error) {
+	return value, nil
+}
+
+func (BytesCodec[V]) Decode(data []byte) (V, error) {
+	return V(data), nil
+}
+
+// WithCodec sets the codec used to encode values in snapshots written by Snapshot, SnapshotFile and WithSnapshotFile,
+// instead of encoding them with the key and expiry in the gob stream. Snapshots written with a codec can only be
+// restored by a cache using an equivalent codec.
+func WithCodec[K comparable, V any](codec Codec[V]) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.codec = codec
+	}
+}
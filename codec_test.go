@@ -0,0 +1,70 @@
+package ugulru_test
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCodec[V any](t *testing.T, codec ugulru.Codec[V], value V) {
+	t.Helper()
+
+	b, err := codec.Encode(value)
+	assert.NoError(t, err)
+
+	decoded, err := codec.Decode(b)
+	assert.NoError(t, err)
+	assert.Equal(t, value, decoded)
+}
+
+func TestCodecs(t *testing.T) {
+	type user struct {
+		Name  string
+		Roles []string
+	}
+
+	testCodec(t, ugulru.GobCodec[user]{}, user{Name: "alice", Roles: []string{"admin"}})
+	testCodec(t, ugulru.JSONCodec[user]{}, user{Name: "bob", Roles: []string{"viewer"}})
+	testCodec(t, ugulru.BytesCodec[[]byte]{}, []byte("raw"))
+
+	b, err := ugulru.JSONCodec[user]{}.Encode(user{Name: "carol"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"Name":"carol","Roles":null}`, string(b))
+
+	_, err = ugulru.JSONCodec[user]{}.Decode([]byte("{"))
+	assert.Error(t, err)
+	_, err = ugulru.GobCodec[user]{}.Decode([]byte("garbage"))
+	assert.Error(t, err)
+}
+
+func TestWithCodec(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(3, 5*time.Minute, ugulru.WithCodec[string, int](ugulru.JSONCodec[int]{}))
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+
+	var buf bytes.Buffer
+	require.NoError(t, cache.Snapshot(&buf))
+
+	restored := ugulru.NewInMemoryCache(3, time.Hour, ugulru.WithCodec[string, int](ugulru.JSONCodec[int]{}))
+	require.NoError(t, restored.Restore(bytes.NewReader(buf.Bytes())))
+	assert.Equal(t, []string{"key2", "key1"}, slices.Collect(restored.Keys()))
+	value, ok := restored.Get("key2")
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+
+	// The values can't be decoded without the codec
+	plain := ugulru.NewInMemoryCache[string, int](3, time.Hour)
+	assert.Error(t, plain.Restore(bytes.NewReader(buf.Bytes())))
+
+	// Snapshots written without a codec can still be restored
+	buf.Reset()
+	plain.Put("key3", 3)
+	require.NoError(t, plain.Snapshot(&buf))
+	require.NoError(t, restored.Restore(&buf))
+	assert.Equal(t, []string{"key3", "key2", "key1"}, slices.Collect(restored.Keys()))
+}
@@ -0,0 +1,29 @@
+package ugulru
+
+import "context"
+
+// contextKey is the key of request-scoped caches in contexts. Every instantiation is a distinct type, so caches with
+// different key or value types don't collide.
+type contextKey[K comparable, V any] struct{}
+
+// NewContext returns a copy of ctx carrying the given request-scoped cache, so nested calls handling the same request
+// can find it with FromContext and reuse it instead of stacking caches of their own.
+func NewContext[K comparable, V any](ctx context.Context, cache Cache[K, V]) context.Context {
+	return context.WithValue(ctx, contextKey[K, V]{}, cache)
+}
+
+// FromContext returns the request-scoped cache with the given key and value types carried by ctx, if any.
+func FromContext[K comparable, V any](ctx context.Context) (Cache[K, V], bool) {
+	cache, ok := ctx.Value(contextKey[K, V]{}).(Cache[K, V])
+	return cache, ok
+}
+
+// EnsureContext returns the request-scoped cache carried by ctx together with ctx itself if there is one. Otherwise, it
+// creates a new cache with newCache and returns it together with a copy of ctx carrying it.
+func EnsureContext[K comparable, V any](ctx context.Context, newCache func() Cache[K, V]) (context.Context, Cache[K, V]) {
+	if cache, ok := FromContext[K, V](ctx); ok {
+		return ctx, cache
+	}
+	cache := newCache()
+	return NewContext(ctx, cache), cache
+}
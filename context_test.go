@@ -0,0 +1,47 @@
+package ugulru_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	_, ok := ugulru.FromContext[string, int](ctx)
+	assert.False(t, ok)
+
+	cache := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+	ctx = ugulru.NewContext[string, int](ctx, cache)
+
+	found, ok := ugulru.FromContext[string, int](ctx)
+	assert.True(t, ok)
+	assert.Same(t, cache, found)
+
+	// Caches with other types are not confused with each other
+	_, ok = ugulru.FromContext[string, string](ctx)
+	assert.False(t, ok)
+}
+
+func TestEnsureContext(t *testing.T) {
+	created := 0
+	newCache := func() ugulru.Cache[string, int] {
+		created++
+		return ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+	}
+
+	ctx, outer := ugulru.EnsureContext(context.Background(), newCache)
+	outer.Put("key1", 1)
+
+	// A nested call reuses the cache of the request
+	nested, inner := ugulru.EnsureContext(ctx, newCache)
+	assert.Equal(t, ctx, nested)
+	assert.Same(t, outer, inner)
+	value, ok := inner.Get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, 1, created)
+}
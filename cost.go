@@ -0,0 +1,90 @@
+package ugulru
+
+import "context"
+
+// WithCost makes the cache weigh every entry with the given function and evict the least recently used entries until
+// the total cost of all the entries fits maxCost, e.g. to bound the memory used by values of very different sizes. The
+// number of entries is still limited by the capacity of the cache, so pass a large capacity to limit the cache by cost
+// only. A nil weigher estimates the size of every entry in bytes with EstimateSize. An entry whose cost alone exceeds
+// maxCost evicts all the others but stays in the cache until it is evicted by the next write. The weigher is called
+// with the lock held, so it must not call any methods of the cache.
+func WithCost[K comparable, V any](weigher func(key K, value V) int64, maxCost int64) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.weigher = weigher
+		c.maxCost = maxCost
+	}
+}
+
+// Usage describes the cost of a single entry and the utilization of the cache after the entry was written.
+type Usage struct {
+	// Cost is the cost of the entry, counted against the capacity of the cache.
+	Cost int64
+	// Utilization is the fraction of the capacity of the cache in use, between 0 and 1.
+	Utilization float64
+}
+
+// PutWithUsage works like Put but also returns the cost of the written entry and the resulting utilization of the
+// cache, so callers can make their own caching decisions, e.g. skip caching when the cache is almost full.
+func (c *InMemoryCache[K, V]) PutWithUsage(key K, value V) Usage {
+	c.throttle()
+	defer c.unlock(c.lock())
+
+	entry, _ := c.writeThrough(context.Background(), key, value, nil)
+	return c.usage(entry)
+}
+
+// LoadWithUsage works like Load but also returns the cost of the returned entry and the resulting utilization of the
+// cache.
+func (c *InMemoryCache[K, V]) LoadWithUsage(key K, loader func() (V, error)) (V, Usage, error) {
+	defer c.unlock(c.lock())
+
+	value, err := c.load(key, loader)
+	entry, ok := c.cache[key]
+	if !ok || entry.err != nil {
+		entry = nil
+	}
+	return value, c.usage(entry), err
+}
+
+// Utilization returns the fraction of the capacity of the cache in use, between 0 and 1.
+func (c *InMemoryCache[K, V]) Utilization() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.usage(nil).Utilization
+}
+
+// usage returns the usage of the given entry, which may be nil. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) usage(entry *entry[K, V]) Usage {
+	var u Usage
+	if entry != nil {
+		u.Cost = entry.cost
+	}
+	if c.maxCost > 0 {
+		u.Utilization = max(float64(c.cost)/float64(c.maxCost), float64(c.list.Len())/float64(c.capacity))
+	} else if c.capacity > 0 {
+		u.Utilization = float64(c.list.Len()) / float64(c.capacity)
+	}
+	return u
+}
+
+// weigh returns the cost of an entry with the given key and value: 1 unless WithCost is used. It must be called with
+// the lock held.
+func (c *InMemoryCache[K, V]) weigh(key K, value V) int64 {
+	if c.weigher != nil {
+		return c.weigher(key, value)
+	}
+	if c.maxCost > 0 {
+		return entrySize(key, value)
+	}
+	return 1
+}
+
+// fit evicts entries other than the given one until the cache fits its max cost. It must be called with the lock
+// held.
+func (c *InMemoryCache[K, V]) fit(entry *entry[K, V]) {
+	pinned := entry.pinned
+	entry.pinned = true
+	c.trim()
+	entry.pinned = pinned
+}
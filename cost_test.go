@@ -0,0 +1,107 @@
+package ugulru_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryCache_PutWithUsage(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](4, 5*time.Minute)
+	assert.Equal(t, 0.0, cache.Utilization())
+
+	usage := cache.PutWithUsage("key1", 1)
+	assert.Equal(t, ugulru.Usage{Cost: 1, Utilization: 0.25}, usage)
+
+	usage = cache.PutWithUsage("key2", 2)
+	assert.Equal(t, ugulru.Usage{Cost: 1, Utilization: 0.5}, usage)
+	assert.Equal(t, 0.5, cache.Utilization())
+}
+
+func TestInMemoryCache_LoadWithUsage(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+
+	value, usage, err := cache.LoadWithUsage("key1", func() (int, error) { return 1, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, ugulru.Usage{Cost: 1, Utilization: 0.5}, usage)
+
+	// Cached value
+	value, usage, err = cache.LoadWithUsage("key1", func() (int, error) { return 2, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, ugulru.Usage{Cost: 1, Utilization: 0.5}, usage)
+
+	// Loader error
+	_, usage, err = cache.LoadWithUsage("key2", func() (int, error) { return 0, errors.New("loader error") })
+	assert.Error(t, err)
+	assert.Equal(t, ugulru.Usage{Cost: 0, Utilization: 0.5}, usage)
+}
+
+func TestWithCost(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(100, 5*time.Minute, ugulru.WithCost(func(key string, value []byte) int64 {
+		return int64(len(value))
+	}, 10))
+
+	assert.Equal(t, ugulru.Usage{Cost: 4, Utilization: 0.4}, cache.PutWithUsage("key1", make([]byte, 4)))
+	cache.Put("key2", make([]byte, 4))
+	cache.Get("key1")
+
+	// key2 is the least recently used entry
+	cache.Put("key3", make([]byte, 3))
+	_, ok := cache.Get("key2")
+	assert.False(t, ok)
+	assert.Equal(t, 0.7, cache.Utilization())
+
+	// Growing an entry evicts the others
+	cache.Put("key3", make([]byte, 8))
+	_, ok = cache.Get("key1")
+	assert.False(t, ok)
+	assert.Equal(t, 0.8, cache.Utilization())
+
+	// An oversized entry stays until the next write
+	cache.Put("big", make([]byte, 20))
+	_, ok = cache.Get("big")
+	assert.True(t, ok)
+	assert.Equal(t, 1, cache.Stats().Len)
+	cache.Put("key4", make([]byte, 1))
+	_, ok = cache.Get("big")
+	assert.False(t, ok)
+	assert.Equal(t, 0.1, cache.Utilization())
+}
+
+func TestInMemoryCache_LoadWithUsageLikeLoad(t *testing.T) {
+	errNotFound := errors.New("not found")
+	store := newMapStore[string, int]()
+	store.data["stored"] = 1
+	cache := ugulru.NewInMemoryCache(2, 5*time.Minute,
+		ugulru.WithNegativeCaching[string, int](func(err error) bool { return errors.Is(err, errNotFound) }, time.Minute),
+		ugulru.WithWriteThrough[string, int](store))
+
+	// Negative entries are cached
+	loads := 0
+	for range 2 {
+		_, usage, err := cache.LoadWithUsage("missing", func() (int, error) {
+			loads++
+			return 0, errNotFound
+		})
+		assert.ErrorIs(t, err, errNotFound)
+		assert.Zero(t, usage.Cost)
+	}
+	assert.Equal(t, 1, loads)
+
+	// Misses read through the store
+	value, usage, err := cache.LoadWithUsage("stored", func() (int, error) {
+		return 0, errors.New("loader should not be called")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, int64(1), usage.Cost)
+
+	// Writes go through the store
+	cache.PutWithUsage("written", 2)
+	assert.Equal(t, 2, store.data["written"])
+}
@@ -0,0 +1,47 @@
+package ugulru
+
+import (
+	"context"
+	"time"
+)
+
+// WithDeadlineEviction makes eviction strictly follow the earliest deadline first instead of recency: when the cache
+// is full, the entry that expires the soonest is evicted, regardless of how recently it was used. It suits entries
+// whose useful life is exactly known, such as scheduled jobs or sessions, written with PutWithDeadline. Finding the
+// victim takes time proportional to the number of entries.
+func WithDeadlineEviction[K comparable, V any]() Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.edf = true
+	}
+}
+
+// PutWithDeadline works like Put but makes the entry expire at the given deadline, overriding the TTL of the cache.
+// A deadline in the past means the entry is never served.
+func (c *InMemoryCache[K, V]) PutWithDeadline(key K, value V, deadline time.Time) {
+	c.throttle()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, _ = c.writeThrough(context.Background(), key, value, func(entry *entry[K, V]) {
+		entry.ttl = time.Until(deadline)
+		if entry.ttl == 0 {
+			entry.ttl = -1
+		}
+	})
+}
+
+// earliestDeadline returns the unpinned entry that expires the soonest, preferring the least recently used one among
+// equal deadlines, or nil if all the entries are pinned. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) earliestDeadline() *entry[K, V] {
+	victim := c.evictable(c.list.Back())
+	if victim == nil {
+		return nil
+	}
+	deadline := c.expiresAt(victim)
+	for e := c.evictable(victim.Prev()); e != nil; e = c.evictable(e.Prev()) {
+		if expiry := c.expiresAt(e); expiry < deadline {
+			victim, deadline = e, expiry
+		}
+	}
+	return victim
+}
@@ -0,0 +1,48 @@
+package ugulru_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDeadlineEviction(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(3, time.Hour, ugulru.WithDeadlineEviction[string, int]())
+	now := time.Now()
+
+	cache.PutWithDeadline("job1", 1, now.Add(3*time.Minute))
+	cache.PutWithDeadline("job2", 2, now.Add(time.Minute))
+	cache.PutWithDeadline("job3", 3, now.Add(2*time.Minute))
+
+	// job2 is the most recently used but has the earliest deadline
+	cache.Get("job2")
+	cache.PutWithDeadline("job4", 4, now.Add(4*time.Minute))
+
+	_, ok := cache.Get("job2")
+	assert.False(t, ok)
+	for _, key := range []string{"job1", "job3", "job4"} {
+		_, ok := cache.Get(key)
+		assert.True(t, ok, key)
+	}
+
+	// Entries without a deadline expire after the TTL of the cache
+	cache.Put("job5", 5)
+	_, ok = cache.Get("job3")
+	assert.False(t, ok)
+}
+
+func TestInMemoryCache_PutWithDeadline(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, time.Hour)
+	deadline := time.Now().Add(time.Minute)
+
+	cache.PutWithDeadline("key", 1, deadline)
+	_, expiry, ok := cache.GetWithExpiry("key")
+	assert.True(t, ok)
+	assert.WithinDuration(t, deadline, expiry, 2*time.Millisecond)
+
+	cache.PutWithDeadline("past", 2, time.Now().Add(-time.Second))
+	_, ok = cache.Get("past")
+	assert.False(t, ok)
+}
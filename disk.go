@@ -0,0 +1,142 @@
+package ugulru
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// DiskStore is a Store keeping each value in its own file in a local directory. It is meant as the overflow tier of a
+// cache (see WithOverflow) for values that are expensive to recompute but cheap to read back. File names are derived
+// from a hash of the encoded keys, and files are replaced atomically, so concurrent readers never see a partial value.
+type DiskStore[K comparable, V any] struct {
+	dir    string
+	keys   KeyCodec[K]
+	values Codec[V]
+}
+
+var _ StreamStore[string, any] = (*DiskStore[string, any])(nil)
+
+// NewDiskStore creates a new disk store in the given directory, which is created if it doesn't exist. Keys are encoded
+// with the given key codec and values with the given codec.
+func NewDiskStore[K comparable, V any](dir string, keys KeyCodec[K], values Codec[V]) (*DiskStore[K, V], error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &DiskStore[K, V]{dir: dir, keys: keys, values: values}, nil
+}
+
+// Get returns the value stored for the given key, or ErrNotFound if there is none.
+func (s *DiskStore[K, V]) Get(_ context.Context, key K) (V, error) {
+	var zero V
+	path, err := s.path(key)
+	if err != nil {
+		return zero, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return zero, ErrNotFound
+	}
+	if err != nil {
+		return zero, err
+	}
+	return s.values.Decode(data)
+}
+
+// Set stores the value for the given key, replacing the previous one.
+func (s *DiskStore[K, V]) Set(_ context.Context, key K, value V) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	data, err := s.values.Encode(value)
+	if err != nil {
+		return err
+	}
+	return s.replace(path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// PutReader stores the size bytes read from r as the encoded value for the given key, replacing the previous one,
+// without holding them in memory, e.g. for multi-megabyte blobs. When the store is the overflow tier of a cache, write
+// through InMemoryCache.PutReader instead, so the cache knows about the value. The bytes are stored as is, so Get
+// decodes them with the value codec; use BytesCodec to read them back unchanged. If r holds fewer or more than size
+// bytes, nothing is stored and an error is returned.
+func (s *DiskStore[K, V]) PutReader(key K, r io.Reader, size int64) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	return s.replace(path, func(w io.Writer) error {
+		n, err := io.Copy(w, io.LimitReader(r, size+1))
+		switch {
+		case err != nil:
+			return err
+		case n != size:
+			return fmt.Errorf("ugulru: read %d bytes instead of %d", n, size)
+		}
+		return nil
+	})
+}
+
+// GetReader returns a reader streaming the encoded value stored for the given key, which must be closed once read,
+// and whether the key was found. Replacing or deleting the value doesn't affect a reader that is already open.
+func (s *DiskStore[K, V]) GetReader(key K) (io.ReadCloser, bool) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, false
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	return f, true
+}
+
+// Delete deletes the value stored for the given key. Deleting a missing key is not an error.
+func (s *DiskStore[K, V]) Delete(_ context.Context, key K) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// replace atomically replaces the file at the given path with the contents written by the given function.
+func (s *DiskStore[K, V]) replace(path string, write func(w io.Writer) error) error {
+	f, err := os.CreateTemp(s.dir, ".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// path returns the path of the file holding the value for the given key.
+func (s *DiskStore[K, V]) path(key K) (string, error) {
+	encoded, err := s.keys.EncodeKey(key)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(encoded))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])), nil
+}
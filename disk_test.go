@@ -0,0 +1,67 @@
+package ugulru_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := ugulru.NewDiskStore[string, string](dir, ugulru.StringKeyCodec[string]{}, ugulru.JSONCodec[string]{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = store.Get(ctx, "key1")
+	assert.ErrorIs(t, err, ugulru.ErrNotFound)
+
+	require.NoError(t, store.Set(ctx, "key1", "value1"))
+	require.NoError(t, store.Set(ctx, "key1", "value2"))
+	value, err := store.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "value2", value)
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	require.NoError(t, store.Delete(ctx, "key1"))
+	require.NoError(t, store.Delete(ctx, "key1"))
+	_, err = store.Get(ctx, "key1")
+	assert.ErrorIs(t, err, ugulru.ErrNotFound)
+}
+
+func TestDiskStore_Reader(t *testing.T) {
+	store, err := ugulru.NewDiskStore[string, []byte](t.TempDir(), ugulru.StringKeyCodec[string]{}, ugulru.BytesCodec[[]byte]{})
+	require.NoError(t, err)
+
+	_, ok := store.GetReader("blob")
+	assert.False(t, ok)
+
+	blob := bytes.Repeat([]byte("x"), 1<<20)
+	require.NoError(t, store.PutReader("blob", bytes.NewReader(blob), int64(len(blob))))
+	r, ok := store.GetReader("blob")
+	require.True(t, ok)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, blob, data)
+
+	value, err := store.Get(context.Background(), "blob")
+	require.NoError(t, err)
+	assert.Equal(t, blob, value)
+
+	// A size mismatch leaves the previous value in place
+	assert.Error(t, store.PutReader("blob", strings.NewReader("short"), 10))
+	assert.Error(t, store.PutReader("blob", strings.NewReader("too long"), 3))
+	value, err = store.Get(context.Background(), "blob")
+	require.NoError(t, err)
+	assert.Equal(t, blob, value)
+}
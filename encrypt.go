@@ -0,0 +1,105 @@
+package ugulru
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// snapshotAD is the additional data authenticated with encrypted snapshots, so ciphertexts produced with the same key
+// for other purposes can't be restored as snapshots.
+var snapshotAD = []byte("ugulru snapshot")
+
+// WithEncryption encrypts the snapshots written by Snapshot, SnapshotFile and WithSnapshotFile with the given AEAD,
+// such as AES-GCM created with cipher.NewGCM, so cached personal data can be persisted to disk. Restore then requires
+// the same key and fails if the snapshot was tampered with. A random nonce is generated for every snapshot and written
+// before the ciphertext.
+func WithEncryption[K comparable, V any](aead cipher.AEAD) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.aead = aead
+	}
+}
+
+// seal encrypts the given plaintext with the AEAD of the cache and writes the nonce and the ciphertext to w.
+func (c *InMemoryCache[K, V]) seal(w io.Writer, plaintext []byte) error {
+	data, err := encrypt(c.aead, plaintext, snapshotAD)
+	if err != nil {
+		return fmt.Errorf("ugulru: encrypt snapshot: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("ugulru: write snapshot: %w", err)
+	}
+	return nil
+}
+
+// open reads a snapshot encrypted by seal from r and returns the decrypted plaintext.
+func (c *InMemoryCache[K, V]) open(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("ugulru: read snapshot: %w", err)
+	}
+	plaintext, err := decrypt(c.aead, data, snapshotAD)
+	if err != nil {
+		return nil, fmt.Errorf("ugulru: decrypt snapshot: %w", err)
+	}
+	return plaintext, nil
+}
+
+// encrypt encrypts the given plaintext and authenticates it with the given additional data, returning a random nonce
+// followed by the ciphertext.
+func encrypt(aead cipher.AEAD, plaintext, ad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, ad), nil
+}
+
+// decrypt decrypts data produced by encrypt with the same additional data. It decrypts the ciphertext in place.
+func decrypt(aead cipher.AEAD, data, ad []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(ciphertext[:0], nonce, ciphertext, ad)
+}
+
+// AEADCodec is a Codec that encrypts the values encoded by another codec with an AEAD, so they are never stored in
+// the clear. Every value is encrypted with a random nonce and authenticated with the additional data of the codec, so
+// values encrypted for another purpose with the same key fail to decode.
+type AEADCodec[V any] struct {
+	codec Codec[V]
+	aead  cipher.AEAD
+	ad    []byte
+}
+
+// NewAEADCodec creates a codec encrypting the values encoded by the given codec with the given AEAD, such as AES-GCM
+// created with cipher.NewGCM, and authenticating them with the given additional data.
+func NewAEADCodec[V any](codec Codec[V], aead cipher.AEAD, ad []byte) *AEADCodec[V] {
+	return &AEADCodec[V]{codec: codec, aead: aead, ad: ad}
+}
+
+func (c *AEADCodec[V]) Encode(value V) ([]byte, error) {
+	plaintext, err := c.codec.Encode(value)
+	if err != nil {
+		return nil, err
+	}
+	data, err := encrypt(c.aead, plaintext, c.ad)
+	if err != nil {
+		return nil, fmt.Errorf("ugulru: encrypt value: %w", err)
+	}
+	return data, nil
+}
+
+// Decode decrypts the given data, which it doesn't modify, and decodes the value.
+func (c *AEADCodec[V]) Decode(data []byte) (V, error) {
+	plaintext, err := decrypt(c.aead, bytes.Clone(data), c.ad)
+	if err != nil {
+		var zero V
+		return zero, fmt.Errorf("ugulru: decrypt value: %w", err)
+	}
+	return c.codec.Decode(plaintext)
+}
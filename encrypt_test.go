@@ -0,0 +1,74 @@
+package ugulru_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAEAD(t *testing.T, key string) cipher.AEAD {
+	t.Helper()
+
+	block, err := aes.NewCipher([]byte(key))
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	return aead
+}
+
+func TestWithEncryption(t *testing.T) {
+	aead := newAEAD(t, "0123456789abcdef0123456789abcdef")
+	cache := ugulru.NewInMemoryCache(3, 5*time.Minute, ugulru.WithEncryption[string, string](aead))
+	cache.Put("email", "alice@example.com")
+	cache.Put("phone", "+1 555 0100")
+
+	var buf bytes.Buffer
+	require.NoError(t, cache.Snapshot(&buf))
+	assert.NotContains(t, buf.String(), "alice@example.com")
+	assert.NotContains(t, buf.String(), "email")
+
+	restored := ugulru.NewInMemoryCache(3, time.Hour, ugulru.WithEncryption[string, string](aead))
+	require.NoError(t, restored.Restore(bytes.NewReader(buf.Bytes())))
+	assert.Equal(t, []string{"phone", "email"}, slices.Collect(restored.Keys()))
+
+	// A different key, a tampered snapshot or a missing key can't restore the snapshot
+	other := ugulru.NewInMemoryCache(3, time.Hour, ugulru.WithEncryption[string, string](newAEAD(t, "fedcba9876543210fedcba9876543210")))
+	assert.Error(t, other.Restore(bytes.NewReader(buf.Bytes())))
+
+	tampered := bytes.Clone(buf.Bytes())
+	tampered[len(tampered)-1] ^= 1
+	assert.Error(t, restored.Restore(bytes.NewReader(tampered)))
+	assert.Error(t, restored.Restore(bytes.NewReader(nil)))
+
+	plain := ugulru.NewInMemoryCache[string, string](3, time.Hour)
+	assert.Error(t, plain.Restore(bytes.NewReader(buf.Bytes())))
+	assert.Empty(t, slices.Collect(plain.Keys()))
+}
+
+func TestAEADCodec(t *testing.T) {
+	codec := ugulru.NewAEADCodec(ugulru.JSONCodec[string]{}, newAEAD(t, "0123456789abcdef0123456789abcdef"), []byte("users"))
+	data, err := codec.Encode("alice@example.com")
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "alice")
+
+	value, err := codec.Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", value)
+	// Decoding leaves the data intact
+	value, err = codec.Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", value)
+
+	other := ugulru.NewAEADCodec(ugulru.JSONCodec[string]{}, newAEAD(t, "0123456789abcdef0123456789abcdef"), []byte("orders"))
+	_, err = other.Decode(data)
+	assert.Error(t, err)
+	_, err = codec.Decode(data[:4])
+	assert.Error(t, err)
+}
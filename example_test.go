@@ -0,0 +1,176 @@
+package ugulru_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/machine23/ugulru"
+)
+
+func ExampleInMemoryCache_Load() {
+	cache := ugulru.NewInMemoryCache[string, string](100, time.Minute)
+
+	loader := func() (string, error) {
+		fmt.Println("loading")
+		return "value", nil
+	}
+	for range 2 {
+		value, err := cache.Load("key", loader)
+		fmt.Println(value, err)
+	}
+	// Output:
+	// loading
+	// value <nil>
+	// value <nil>
+}
+
+func ExampleInMemoryCache_LoadMulti() {
+	cache := ugulru.NewInMemoryCache[int, string](100, time.Minute)
+	cache.Put(1, "one")
+
+	found, err := cache.LoadMulti([]int{1, 2, 3}, func(missing []int) (map[int]string, error) {
+		fmt.Println("loading", missing)
+		return map[int]string{2: "two", 3: "three"}, nil
+	})
+	fmt.Println(found, err)
+	// Output:
+	// loading [2 3]
+	// map[1:one 2:two 3:three] <nil>
+}
+
+func ExampleNewLoadingCache() {
+	cache := ugulru.NewLoadingCache(100, time.Minute, func(ctx context.Context, key string) (int, error) {
+		return len(key), nil
+	})
+
+	value, err := cache.Get(context.Background(), "hello")
+	fmt.Println(value, err)
+	// Output:
+	// 5 <nil>
+}
+
+func ExampleWithNegativeCaching() {
+	errNotFound := errors.New("not found")
+	cache := ugulru.NewInMemoryCache(100, time.Minute, ugulru.WithNegativeCaching[string, string](
+		func(err error) bool { return errors.Is(err, errNotFound) },
+		10*time.Second,
+	))
+
+	loader := func() (string, error) {
+		fmt.Println("querying the database")
+		return "", errNotFound
+	}
+	for range 2 {
+		_, err := cache.Load("missing", loader)
+		fmt.Println(err)
+	}
+	// Output:
+	// querying the database
+	// not found
+	// not found
+}
+
+func ExampleFederation() {
+	sessions := ugulru.NewInMemoryCache[string, string](1000, 30*time.Minute)
+	pages := ugulru.NewInMemoryCache[string, string](100, time.Minute)
+
+	federation := ugulru.NewFederation[string, string](pages)
+	federation.Register(func(key string) bool { return strings.HasPrefix(key, "session:") }, sessions)
+
+	federation.Put("session:42", "alice")
+	federation.Put("/index.html", "<html></html>")
+
+	_, ok := sessions.Get("session:42")
+	fmt.Println(ok)
+	_, ok = pages.Get("/index.html")
+	fmt.Println(ok)
+	// Output:
+	// true
+	// true
+}
+
+func ExampleNewCacheAside() {
+	ctx := context.Background()
+	store := newMapStore[string, int]()
+	cache := ugulru.NewInMemoryCache[string, int](100, time.Minute)
+	aside := ugulru.NewCacheAside[string, int](cache, store, 0)
+
+	_ = aside.WriteThrough(ctx, "counter", 1)
+	value, err := aside.GetThrough(ctx, "counter")
+	fmt.Println(value, err)
+
+	_ = aside.WriteThrough(ctx, "counter", 2)
+	value, err = aside.GetThrough(ctx, "counter")
+	fmt.Println(value, err)
+	// Output:
+	// 1 <nil>
+	// 2 <nil>
+}
+
+func ExampleRetry() {
+	attempts := 0
+	loader := ugulru.Retry(func(ctx context.Context) (string, error) {
+		attempts++
+		if attempts < 3 {
+			return "", errors.New("transient error")
+		}
+		return "value", nil
+	}, 5, time.Millisecond)
+
+	cache := ugulru.NewInMemoryCache[string, string](100, time.Minute)
+	value, err := cache.LoadContext(context.Background(), "key", loader)
+	fmt.Println(value, err, attempts)
+	// Output:
+	// value <nil> 3
+}
+
+func ExampleTieredCache() {
+	local := ugulru.NewInMemoryCache[string, string](100, 10*time.Second)
+	shared := ugulru.NewInMemoryCache[string, string](10000, time.Hour) // e.g. a rediscache.Cache
+	cache := ugulru.NewTieredCache[string, string](local, shared)
+
+	loader := func() (string, error) {
+		fmt.Println("loading")
+		return "value", nil
+	}
+	value, err := cache.Load("key", loader)
+	fmt.Println(value, err)
+
+	local.Remove("key")
+	value, err = cache.Load("key", loader)
+	fmt.Println(value, err)
+	// Output:
+	// loading
+	// value <nil>
+	// value <nil>
+}
+
+func ExampleMiddleware() {
+	cache := ugulru.NewInMemoryCache[string, ugulru.CachedResponse](1000, time.Minute)
+	cached := ugulru.Middleware(cache, ugulru.MiddlewareConfig{
+		Vary: []string{"Accept-Encoding"},
+		TTL: func(r *http.Request) time.Duration {
+			if strings.HasPrefix(r.URL.Path, "/news/") {
+				return 10 * time.Second
+			}
+			return 0
+		},
+	})
+
+	handler := cached(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "rendered", r.URL.Path)
+	}))
+	for range 2 {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/news/today", nil))
+		fmt.Print(rec.Header().Get("X-Cache"), " ", rec.Body.String())
+	}
+	// Output:
+	// MISS rendered /news/today
+	// HIT rendered /news/today
+}
@@ -0,0 +1,49 @@
+package ugulru
+
+// ProtectedLen returns the number of entries in the protected segment of the cache.
+func (c *InMemoryCache[K, V]) ProtectedLen() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.protectedLen
+}
+
+// WindowLen returns the number of entries in the TinyLFU admission window of the cache.
+func (c *InMemoryCache[K, V]) WindowLen() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.windowLen
+}
+
+// SketchWidth returns the number of counters in every row of the TinyLFU sketch of the cache.
+func (c *InMemoryCache[K, V]) SketchWidth() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.sketch.rows[0])
+}
+
+// Estimate returns the access frequency of the given key estimated by the TinyLFU sketch of the cache.
+func (c *InMemoryCache[K, V]) Estimate(key K) uint8 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.sketch.estimate(hashKey(key))
+}
+
+// HashKey exposes hashKey to the tests.
+func HashKey[K comparable](key K) uint64 {
+	return hashKey(key)
+}
+
+// BreakerKeys returns the number of keys with a circuit breaker state.
+func (c *InMemoryCache[K, V]) BreakerKeys() int {
+	c.breaker.mu.Lock()
+	defer c.breaker.mu.Unlock()
+
+	return len(c.breaker.states)
+}
+
+// RetryDelay exposes retryDelay to the tests.
+var RetryDelay = retryDelay
@@ -0,0 +1,91 @@
+package ugulru
+
+import "sync"
+
+// Federation is a Cache that routes every operation to one of several registered caches based on the key, e.g.
+// session keys to one cache and blobs to another, so application code doesn't have to know which cache holds what.
+type Federation[K comparable, V any] struct {
+	mu       sync.RWMutex
+	routes   []route[K, V]
+	fallback Cache[K, V]
+}
+
+type route[K comparable, V any] struct {
+	match func(key K) bool
+	cache Cache[K, V]
+}
+
+var _ Cache[string, any] = (*Federation[string, any])(nil)
+
+// NewFederation creates a new federation that routes the keys not matched by any registered cache to fallback. If
+// fallback is nil, such keys are never cached.
+func NewFederation[K comparable, V any](fallback Cache[K, V]) *Federation[K, V] {
+	return &Federation[K, V]{fallback: fallback}
+}
+
+// Register routes the keys matched by the given predicate to the given cache. Predicates are evaluated in the order
+// the caches were registered and the first match wins.
+func (f *Federation[K, V]) Register(match func(key K) bool, cache Cache[K, V]) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.routes = append(f.routes, route[K, V]{match: match, cache: cache})
+}
+
+// Get retrieves a value from the cache the key is routed to.
+func (f *Federation[K, V]) Get(key K) (V, bool) {
+	if cache := f.route(key); cache != nil {
+		return cache.Get(key)
+	}
+	var zero V
+	return zero, false
+}
+
+// Put inserts or updates the value in the cache the key is routed to.
+func (f *Federation[K, V]) Put(key K, value V) {
+	if cache := f.route(key); cache != nil {
+		cache.Put(key, value)
+	}
+}
+
+// Remove deletes the entry from the cache the key is routed to.
+func (f *Federation[K, V]) Remove(key K) {
+	if cache := f.route(key); cache != nil {
+		cache.Remove(key)
+	}
+}
+
+// RemoveExpired removes all expired entries from all the federated caches.
+func (f *Federation[K, V]) RemoveExpired() {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for _, r := range f.routes {
+		r.cache.RemoveExpired()
+	}
+	if f.fallback != nil {
+		f.fallback.RemoveExpired()
+	}
+}
+
+// Load retrieves the value from the cache the key is routed to, calling the loader on a miss. If the key is not routed
+// to any cache, the loader is always called and its value is not cached.
+func (f *Federation[K, V]) Load(key K, loader func() (V, error)) (V, error) {
+	if cache := f.route(key); cache != nil {
+		return cache.Load(key, loader)
+	}
+	return loader()
+}
+
+// route returns the cache the given key is routed to, or nil if there is none.
+func (f *Federation[K, V]) route(key K) Cache[K, V] {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for _, r := range f.routes {
+		if r.match(key) {
+			return r.cache
+		}
+	}
+	return f.fallback
+}
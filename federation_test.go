@@ -0,0 +1,69 @@
+package ugulru_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFederation(t *testing.T) {
+	sessions := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+	blobs := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+	other := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+
+	federation := ugulru.NewFederation[string, int](other)
+	federation.Register(func(key string) bool { return strings.HasPrefix(key, "session:") }, sessions)
+	federation.Register(func(key string) bool { return strings.HasPrefix(key, "blob:") }, blobs)
+
+	federation.Put("session:1", 1)
+	federation.Put("blob:1", 2)
+	federation.Put("user:1", 3)
+
+	// Entries end up in the matching caches
+	value, ok := sessions.Get("session:1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	value, ok = blobs.Get("blob:1")
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+	value, ok = other.Get("user:1")
+	assert.True(t, ok)
+	assert.Equal(t, 3, value)
+
+	value, ok = federation.Get("blob:1")
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+
+	value, err := federation.Load("session:2", func() (int, error) { return 4, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 4, value)
+	_, ok = sessions.Get("session:2")
+	assert.True(t, ok)
+
+	federation.Remove("session:1")
+	_, ok = sessions.Get("session:1")
+	assert.False(t, ok)
+}
+
+func TestFederation_NoFallback(t *testing.T) {
+	federation := ugulru.NewFederation[string, int](nil)
+
+	federation.Put("key1", 1)
+	_, ok := federation.Get("key1")
+	assert.False(t, ok)
+
+	calls := 0
+	loader := func() (int, error) {
+		calls++
+		return 1, nil
+	}
+	federation.Load("key1", loader)
+	federation.Load("key1", loader)
+	assert.Equal(t, 2, calls)
+
+	federation.Remove("key1")
+	federation.RemoveExpired()
+}
@@ -0,0 +1,70 @@
+package ugulru
+
+import (
+	"context"
+	"sync"
+)
+
+// barrier tracks the background work in progress and lets callers wait for it to complete.
+type barrier struct {
+	mu      sync.Mutex
+	pending int
+	waiters []chan struct{}
+}
+
+func (b *barrier) add() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pending++
+}
+
+func (b *barrier) done() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pending--
+	if b.pending == 0 {
+		for _, ch := range b.waiters {
+			close(ch)
+		}
+		b.waiters = nil
+	}
+}
+
+func (b *barrier) wait(ctx context.Context) error {
+	b.mu.Lock()
+	if b.pending == 0 {
+		b.mu.Unlock()
+		return nil
+	}
+	ch := make(chan struct{})
+	b.waiters = append(b.waiters, ch)
+	b.mu.Unlock()
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Flush blocks until all the background work started by the cache, such as stale-while-revalidate and refresh-ahead
+// reloads or queued write-behind writes, has completed, or until the context is done, in which case the context error
+// is returned. It is meant for graceful shutdown and for tests that need to observe the results of background work.
+func (c *InMemoryCache[K, V]) Flush(ctx context.Context) error {
+	if c.behind != nil {
+		c.behind.flush()
+	}
+	return c.background.wait(ctx)
+}
+
+// goBackground runs fn in a new goroutine tracked by Flush.
+func (c *InMemoryCache[K, V]) goBackground(fn func()) {
+	c.background.add()
+	go func() {
+		defer c.background.done()
+		fn()
+	}()
+}
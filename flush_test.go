@@ -0,0 +1,34 @@
+package ugulru_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryCache_Flush(t *testing.T) {
+	loader := func(key string) (int, error) {
+		time.Sleep(100 * time.Millisecond)
+		return 2, nil
+	}
+	cache := ugulru.NewInMemoryCache(2, 5*time.Minute, ugulru.WithRefreshAhead(0, loader))
+
+	// Nothing to wait for
+	assert.NoError(t, cache.Flush(context.Background()))
+
+	cache.Put("key1", 1)
+	time.Sleep(time.Millisecond)
+	cache.Get("key1") // starts a refresh
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, cache.Flush(ctx), context.DeadlineExceeded)
+
+	assert.NoError(t, cache.Flush(context.Background()))
+	value, ok := cache.Get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+}
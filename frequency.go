@@ -0,0 +1,63 @@
+package ugulru
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+)
+
+// HotKey is a key together with its access frequency.
+type HotKey[K comparable] struct {
+	Key       K
+	Frequency float64
+}
+
+// WithFrequencyHalfLife makes the access frequencies of the entries decay exponentially with the given half-life, so
+// keys that were hot in the past but are not accessed anymore gradually lose their rank. Without this option the
+// frequencies are plain access counts.
+func WithFrequencyHalfLife[K comparable, V any](halfLife time.Duration) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.halfLife = halfLife
+	}
+}
+
+// TopN returns up to n live keys with the highest access frequencies, in descending order of frequency. Every read
+// and write of an entry counts as an access. It returns nil if n is not positive.
+func (c *InMemoryCache[K, V]) TopN(n int) []HotKey[K] {
+	if n <= 0 {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock()
+	var hot []HotKey[K]
+	for entry := c.list.Front(); entry != nil; entry = entry.Next() {
+		if c.expired(entry) || entry.err != nil {
+			continue
+		}
+		hot = append(hot, HotKey[K]{Key: entry.key, Frequency: c.frequency(entry, now)})
+	}
+	slices.SortStableFunc(hot, func(a, b HotKey[K]) int {
+		return cmp.Compare(b.Frequency, a.Frequency)
+	})
+	return hot[:min(n, len(hot))]
+}
+
+// touchFrequency records an access to the given entry at the given time, in milliseconds since the epoch of the cache.
+// It must be called with the lock held.
+func (c *InMemoryCache[K, V]) touchFrequency(entry *entry[K, V], now int64) {
+	entry.freq = c.frequency(entry, now) + 1
+	entry.freqAt = now
+}
+
+// frequency returns the access frequency of the given entry, decayed to the given time in milliseconds since the epoch
+// of the cache. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) frequency(entry *entry[K, V], now int64) float64 {
+	if c.halfLife <= 0 || entry.freq == 0 {
+		return entry.freq
+	}
+	halfLives := float64(time.Duration(now-entry.freqAt)*time.Millisecond) / float64(c.halfLife)
+	return entry.freq * math.Exp2(-halfLives)
+}
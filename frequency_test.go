@@ -0,0 +1,46 @@
+package ugulru_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryCache_TopN(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+	cache.Put("key3", 3)
+	for range 3 {
+		cache.Get("key2")
+	}
+	cache.Get("key3")
+
+	assert.Equal(t, []ugulru.HotKey[string]{
+		{Key: "key2", Frequency: 4},
+		{Key: "key3", Frequency: 2},
+	}, cache.TopN(2))
+	assert.Len(t, cache.TopN(10), 3)
+	assert.Nil(t, cache.TopN(0))
+	assert.Nil(t, cache.TopN(-1))
+}
+
+func TestWithFrequencyHalfLife(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(2, 5*time.Minute, ugulru.WithFrequencyHalfLife[string, int](100*time.Millisecond))
+	cache.Put("key1", 1)
+	for range 7 {
+		cache.Get("key1")
+	}
+
+	// key1 was hot but hasn't been accessed for a few half-lives
+	time.Sleep(400 * time.Millisecond)
+	cache.Put("key2", 2)
+	cache.Get("key2")
+
+	top := cache.TopN(2)
+	assert.Equal(t, "key2", top[0].Key)
+	assert.Equal(t, "key1", top[1].Key)
+	assert.InDelta(t, 0.5, top[1].Frequency, 0.2)
+}
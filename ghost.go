@@ -0,0 +1,79 @@
+package ugulru
+
+// CapacityEstimate is the hit ratio the cache would achieve with a hypothetical capacity.
+type CapacityEstimate struct {
+	// Capacity is the hypothetical capacity.
+	Capacity int
+	// HitRatio is the estimated hit ratio with the given capacity, between 0 and 1.
+	HitRatio float64
+}
+
+// WithGhostCaches helps with capacity planning by estimating the hit ratio the cache would achieve if its capacity
+// were the given multiples of the actual one, e.g. 2 and 4. For every multiple, the cache keeps a ghost LRU cache
+// that tracks keys but not values and replays every lookup; see CapacityEstimates. Ghost caches ignore expiration and
+// the other eviction policies, and cost memory and time proportional to their capacities. It panics with
+// NewInMemoryCacheWithMaxBytes, whose capacity is unbounded.
+func WithGhostCaches[K comparable, V any](multiples ...int) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		if c.unbounded() {
+			panic("ugulru: WithGhostCaches requires a bounded capacity")
+		}
+		for _, m := range multiples {
+			c.ghosts = append(c.ghosts, newGhost[K](c.capacity*m))
+		}
+	}
+}
+
+// CapacityEstimates returns the estimated hit ratios for the hypothetical capacities configured with WithGhostCaches,
+// in the order they were given.
+func (c *InMemoryCache[K, V]) CapacityEstimates() []CapacityEstimate {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	estimates := make([]CapacityEstimate, 0, len(c.ghosts))
+	for _, g := range c.ghosts {
+		estimate := CapacityEstimate{Capacity: g.capacity}
+		if total := g.hits + g.misses; total > 0 {
+			estimate.HitRatio = float64(g.hits) / float64(total)
+		}
+		estimates = append(estimates, estimate)
+	}
+	return estimates
+}
+
+// ghost is an LRU cache of keys only, counting the hits and misses of the lookups replayed on it.
+type ghost[K comparable] struct {
+	keys     map[K]*ghostKey[K]
+	list     linkedList[ghostKey[K], *ghostKey[K]]
+	capacity int
+	hits     uint64
+	misses   uint64
+}
+
+// ghostKey is a key tracked without a value, in a ghost cache or in the ghost queue of an S3FIFOCache.
+type ghostKey[K comparable] struct {
+	listLinks[ghostKey[K]]
+	key K
+}
+
+func newGhost[K comparable](capacity int) *ghost[K] {
+	g := &ghost[K]{keys: make(map[K]*ghostKey[K]), capacity: capacity}
+	g.list.init()
+	return g
+}
+
+// access records a lookup of the given key. A missing key is assumed to be loaded into the cache.
+func (g *ghost[K]) access(key K) {
+	if k, ok := g.keys[key]; ok {
+		g.hits++
+		g.list.MoveToFront(k)
+		return
+	}
+	g.misses++
+	g.keys[key] = g.list.PushFront(&ghostKey[K]{key: key})
+	if g.list.Len() > g.capacity {
+		oldest := g.list.Back()
+		g.list.Remove(oldest)
+		delete(g.keys, oldest.key)
+	}
+}
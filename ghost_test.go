@@ -0,0 +1,28 @@
+package ugulru_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithGhostCaches(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(2, 5*time.Minute, ugulru.WithGhostCaches[int, int](2, 4))
+	load := func() (int, error) { return 0, nil }
+
+	// A cyclic scan over 4 keys always misses with a capacity of 2 but always hits with 4 or more after warmup
+	for range 5 {
+		for key := range 4 {
+			_, _ = cache.Load(key, load)
+		}
+	}
+
+	stats := cache.Stats()
+	assert.Equal(t, uint64(0), stats.Hits)
+	assert.Equal(t, []ugulru.CapacityEstimate{
+		{Capacity: 4, HitRatio: 0.8},
+		{Capacity: 8, HitRatio: 0.8},
+	}, cache.CapacityEstimates())
+}
@@ -0,0 +1,106 @@
+package ugulru
+
+import (
+	"sync"
+
+	"github.com/machine23/ugulru/internal/ring"
+)
+
+// DefaultVirtualNodes is the number of points each node is placed at on the ring of a HashRingCache when the given
+// number is not positive.
+const DefaultVirtualNodes = 160
+
+// HashRingCache is a Cache that spreads keys across several caches, typically remote ones such as Redis or memcached
+// caches, using consistent hashing with virtual nodes, to scale a shared cache tier horizontally. Adding or removing a
+// node only moves the keys of that node, about 1/n of the keys with n nodes, which then miss once. It is safe for
+// concurrent use, and nodes can be added and removed while in use.
+type HashRingCache[K comparable, V any] struct {
+	mu   sync.RWMutex
+	ring *ring.Ring[Cache[K, V]]
+	keys KeyCodec[K]
+}
+
+var _ Cache[string, any] = (*HashRingCache[string, any])(nil)
+
+// NewHashRingCache creates a new hash ring cache without nodes, placing each node at the given number of points on the
+// ring. Keys are hashed in the form given by the key codec.
+func NewHashRingCache[K comparable, V any](keys KeyCodec[K], virtualNodes int) *HashRingCache[K, V] {
+	if virtualNodes <= 0 {
+		virtualNodes = DefaultVirtualNodes
+	}
+	return &HashRingCache[K, V]{ring: ring.New[Cache[K, V]](virtualNodes), keys: keys}
+}
+
+// AddNode adds the given cache to the ring under the given name, replacing the cache with the same name if any. The
+// name determines the position of the node on the ring, so it must be stable, e.g. the address of the server.
+func (h *HashRingCache[K, V]) AddNode(name string, cache Cache[K, V]) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.ring.Add(name, cache)
+}
+
+// RemoveNode removes the cache with the given name from the ring. Its keys move to the remaining nodes.
+func (h *HashRingCache[K, V]) RemoveNode(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.ring.Remove(name)
+}
+
+// node returns the cache the given key belongs to, or nil if the ring is empty or the key can't be encoded.
+func (h *HashRingCache[K, V]) node(key K) Cache[K, V] {
+	encoded, err := h.keys.EncodeKey(key)
+	if err != nil {
+		return nil
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	cache, _ := h.ring.Get(encoded)
+	return cache
+}
+
+// Get retrieves a value from the node the key belongs to.
+func (h *HashRingCache[K, V]) Get(key K) (V, bool) {
+	if cache := h.node(key); cache != nil {
+		return cache.Get(key)
+	}
+	var zero V
+	return zero, false
+}
+
+// Put inserts or updates the value in the node the key belongs to.
+func (h *HashRingCache[K, V]) Put(key K, value V) {
+	if cache := h.node(key); cache != nil {
+		cache.Put(key, value)
+	}
+}
+
+// Remove deletes the entry from the node the key belongs to.
+func (h *HashRingCache[K, V]) Remove(key K) {
+	if cache := h.node(key); cache != nil {
+		cache.Remove(key)
+	}
+}
+
+// RemoveExpired removes all expired entries from all the nodes.
+func (h *HashRingCache[K, V]) RemoveExpired() {
+	h.mu.RLock()
+	nodes := h.ring.Nodes()
+	h.mu.RUnlock()
+
+	for _, cache := range nodes {
+		cache.RemoveExpired()
+	}
+}
+
+// Load retrieves the value from the node the key belongs to, using the Load semantics of that node. Without nodes,
+// the loader is called directly.
+func (h *HashRingCache[K, V]) Load(key K, loader func() (V, error)) (V, error) {
+	if cache := h.node(key); cache != nil {
+		return cache.Load(key, loader)
+	}
+	return loader()
+}
@@ -0,0 +1,59 @@
+package ugulru_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashRingCache(t *testing.T) {
+	cache := ugulru.NewHashRingCache[int, int](ugulru.IntKeyCodec[int]{}, 0)
+
+	// Without nodes, nothing is cached
+	cache.Put(1, 1)
+	_, ok := cache.Get(1)
+	assert.False(t, ok)
+	value, err := cache.Load(1, func() (int, error) { return 1, nil })
+	require.NoError(t, err)
+	assert.Equal(t, 1, value)
+
+	nodes := make([]*ugulru.InMemoryCache[int, int], 4)
+	for i := range nodes {
+		nodes[i] = ugulru.NewInMemoryCache[int, int](1000, time.Minute)
+		cache.AddNode(string(rune('a'+i)), nodes[i])
+	}
+
+	const keys = 1000
+	for i := range keys {
+		cache.Put(i, i)
+	}
+	for _, node := range nodes {
+		assert.InDelta(t, keys/4, len(slices.Collect(node.Keys())), keys/10, "keys should be spread evenly")
+	}
+
+	// Removing a node only moves its keys
+	cache.RemoveNode("a")
+	misses := 0
+	for i := range keys {
+		if _, ok := cache.Get(i); !ok {
+			misses++
+		}
+	}
+	assert.Equal(t, len(slices.Collect(nodes[0].Keys())), misses)
+
+	// Adding a node back only moves the keys it owns
+	cache.AddNode("a", nodes[0])
+	for i := range keys {
+		value, ok := cache.Get(i)
+		assert.True(t, ok)
+		assert.Equal(t, i, value)
+	}
+
+	cache.Remove(1)
+	_, ok = cache.Get(1)
+	assert.False(t, ok)
+}
@@ -0,0 +1,71 @@
+package ugulru
+
+import (
+	"sync"
+	"time"
+)
+
+// ReservationStatus is the status of an operation identified by an idempotency key.
+type ReservationStatus int
+
+const (
+	// StatusNew means the operation has not been seen before and the caller has reserved it.
+	StatusNew ReservationStatus = iota
+	// StatusInFlight means the operation has been reserved by another caller and has not completed yet.
+	StatusInFlight
+	// StatusCompleted means the operation has completed and its result is available.
+	StatusCompleted
+)
+
+// Idempotency tracks operations identified by idempotency keys, so retried requests are executed at most once. The
+// results of completed operations expire after the TTL, after which the key is treated as new again. Operations in
+// flight are tracked until they are completed or released, regardless of the capacity and the TTL.
+type Idempotency[K comparable, R any] struct {
+	cache *InMemoryCache[K, R]
+
+	mu       sync.Mutex
+	inFlight map[K]struct{}
+}
+
+// NewIdempotency creates a new idempotency tracker keeping the results of up to capacity completed operations for the
+// given TTL duration.
+func NewIdempotency[K comparable, R any](capacity int, ttl time.Duration) *Idempotency[K, R] {
+	return &Idempotency[K, R]{
+		cache:    NewInMemoryCache[K, R](capacity, ttl),
+		inFlight: make(map[K]struct{}),
+	}
+}
+
+// Reserve atomically checks the status of the operation with the given key and reserves it if it is new. The result is
+// only set if the status is StatusCompleted. A caller that gets StatusNew must eventually call Complete or Release.
+func (i *Idempotency[K, R]) Reserve(key K) (ReservationStatus, R) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var zero R
+	if _, ok := i.inFlight[key]; ok {
+		return StatusInFlight, zero
+	}
+	if result, ok := i.cache.Get(key); ok {
+		return StatusCompleted, result
+	}
+	i.inFlight[key] = struct{}{}
+	return StatusNew, zero
+}
+
+// Complete records the result of the operation with the given key, which is returned by Reserve from then on.
+func (i *Idempotency[K, R]) Complete(key K, result R) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.cache.Put(key, result)
+	delete(i.inFlight, key)
+}
+
+// Release drops the reservation of the operation with the given key, e.g. because it failed and may be retried.
+func (i *Idempotency[K, R]) Release(key K) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	delete(i.inFlight, key)
+}
@@ -0,0 +1,75 @@
+package ugulru_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotency(t *testing.T) {
+	idem := ugulru.NewIdempotency[string, string](10, 100*time.Millisecond)
+
+	status, _ := idem.Reserve("op1")
+	assert.Equal(t, ugulru.StatusNew, status)
+	status, _ = idem.Reserve("op1")
+	assert.Equal(t, ugulru.StatusInFlight, status)
+
+	idem.Complete("op1", "done")
+	status, result := idem.Reserve("op1")
+	assert.Equal(t, ugulru.StatusCompleted, status)
+	assert.Equal(t, "done", result)
+
+	// Released operations can be retried
+	status, _ = idem.Reserve("op2")
+	assert.Equal(t, ugulru.StatusNew, status)
+	idem.Release("op2")
+	status, _ = idem.Reserve("op2")
+	assert.Equal(t, ugulru.StatusNew, status)
+
+	// Records expire after the TTL
+	time.Sleep(150 * time.Millisecond)
+	status, _ = idem.Reserve("op1")
+	assert.Equal(t, ugulru.StatusNew, status)
+}
+
+func TestIdempotency_Concurrent(t *testing.T) {
+	idem := ugulru.NewIdempotency[string, int](10, time.Minute)
+
+	var reserved atomic.Int32
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if status, _ := idem.Reserve("op"); status == ugulru.StatusNew {
+				reserved.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), reserved.Load())
+}
+
+func TestIdempotency_InFlightNotEvicted(t *testing.T) {
+	idem := ugulru.NewIdempotency[int, int](2, 50*time.Millisecond)
+
+	status, _ := idem.Reserve(0)
+	assert.Equal(t, ugulru.StatusNew, status)
+	for i := 1; i <= 10; i++ {
+		idem.Reserve(i)
+		idem.Complete(i, i)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	// The reservation outlives both the capacity and the TTL until it is completed
+	status, _ = idem.Reserve(0)
+	assert.Equal(t, ugulru.StatusInFlight, status)
+	idem.Complete(0, 42)
+	status, result := idem.Reserve(0)
+	assert.Equal(t, ugulru.StatusCompleted, status)
+	assert.Equal(t, 42, result)
+}
@@ -0,0 +1,98 @@
+// Package ring implements a consistent hash ring with virtual nodes, which maps keys to nodes so that adding or
+// removing a node only moves the keys of that node.
+package ring
+
+import (
+	"cmp"
+	"hash/fnv"
+	"slices"
+	"strconv"
+)
+
+// Ring maps keys to named nodes. Each node is placed at several points of the ring, its virtual nodes, so keys are
+// spread evenly across nodes. A key belongs to the node owning the first point at or after the hash of the key. A Ring
+// is not safe for concurrent use.
+type Ring[N any] struct {
+	replicas int
+	points   []point
+	nodes    map[string]N
+}
+
+// point is a virtual node on the ring.
+type point struct {
+	hash uint64
+	name string
+}
+
+// New creates an empty ring placing each node at the given number of points.
+func New[N any](replicas int) *Ring[N] {
+	return &Ring[N]{replicas: max(replicas, 1), nodes: make(map[string]N)}
+}
+
+// Add adds the given node with the given name to the ring, replacing the node with the same name if any.
+func (r *Ring[N]) Add(name string, node N) {
+	if _, ok := r.nodes[name]; !ok {
+		for i := range r.replicas {
+			r.points = append(r.points, point{hash: hash(name + "#" + strconv.Itoa(i)), name: name})
+		}
+		slices.SortFunc(r.points, func(a, b point) int {
+			return cmp.Or(cmp.Compare(a.hash, b.hash), cmp.Compare(a.name, b.name))
+		})
+	}
+	r.nodes[name] = node
+}
+
+// Remove removes the node with the given name from the ring.
+func (r *Ring[N]) Remove(name string) {
+	if _, ok := r.nodes[name]; !ok {
+		return
+	}
+	delete(r.nodes, name)
+	r.points = slices.DeleteFunc(r.points, func(p point) bool {
+		return p.name == name
+	})
+}
+
+// Get returns the node the given key belongs to, or false if the ring is empty.
+func (r *Ring[N]) Get(key string) (N, bool) {
+	if len(r.points) == 0 {
+		var zero N
+		return zero, false
+	}
+	h := hash(key)
+	i, _ := slices.BinarySearchFunc(r.points, h, func(p point, h uint64) int {
+		return cmp.Compare(p.hash, h)
+	})
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.nodes[r.points[i].name], true
+}
+
+// Nodes returns the nodes of the ring, sorted by name.
+func (r *Ring[N]) Nodes() []N {
+	names := make([]string, 0, len(r.nodes))
+	for name := range r.nodes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	nodes := make([]N, len(names))
+	for i, name := range names {
+		nodes[i] = r.nodes[name]
+	}
+	return nodes
+}
+
+// hash returns the position of the given string on the ring. FNV-1a is finalized with the mixer of SplitMix64, so
+// similar strings, such as the names of the virtual nodes of a node, are spread over the whole ring.
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
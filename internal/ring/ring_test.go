@@ -0,0 +1,49 @@
+package ring_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/machine23/ugulru/internal/ring"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRing(t *testing.T) {
+	r := ring.New[int](100)
+	_, ok := r.Get("key")
+	assert.False(t, ok)
+
+	for i := range 4 {
+		r.Add("node"+strconv.Itoa(i), i)
+	}
+	assert.Equal(t, []int{0, 1, 2, 3}, r.Nodes())
+
+	const keys = 10000
+	before := make([]int, keys)
+	counts := make(map[int]int)
+	for i := range keys {
+		before[i], _ = r.Get(strconv.Itoa(i))
+		counts[before[i]]++
+	}
+	for node, count := range counts {
+		assert.InDelta(t, keys/4, count, keys/10, "node %d should get about a quarter of the keys", node)
+	}
+
+	// Only the keys of the removed node move
+	r.Remove("node2")
+	for i := range keys {
+		node, _ := r.Get(strconv.Itoa(i))
+		if before[i] != 2 {
+			assert.Equal(t, before[i], node)
+		} else {
+			assert.NotEqual(t, 2, node)
+		}
+	}
+
+	// Adding the node back restores the original mapping
+	r.Add("node2", 2)
+	for i := range keys {
+		node, _ := r.Get(strconv.Itoa(i))
+		assert.Equal(t, before[i], node)
+	}
+}
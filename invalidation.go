@@ -0,0 +1,170 @@
+package ugulru
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// PubSub is a publish-subscribe channel shared by the replicas of a service, such as a Redis or NATS channel. Every
+// published payload is delivered to all the subscribers, including the publisher itself.
+type PubSub interface {
+	// Publish publishes the given payload.
+	Publish(ctx context.Context, payload []byte) error
+	// Subscribe calls handler with every payload published from now on until unsubscribe is called. It returns once the
+	// subscription is active.
+	Subscribe(handler func(payload []byte)) (unsubscribe func() error, err error)
+}
+
+// invalidation is a message published by an Invalidator.
+type invalidation struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys,omitempty"`
+	Values [][]byte `json:"values,omitempty"`
+	All    bool     `json:"all,omitempty"`
+}
+
+// Invalidator keeps the in-memory caches of several replicas of a service coherent: removing a key or clearing the
+// cache through the invalidator of one replica publishes the invalidation, and the invalidators of the other replicas
+// apply it to their cache. Keys are published in the form given by the key codec.
+type Invalidator[K comparable, V any] struct {
+	cache       *InMemoryCache[K, V]
+	pubsub      PubSub
+	keys        KeyCodec[K]
+	values      Codec[V]
+	origin      string
+	unsubscribe func() error
+}
+
+// InvalidatorOption configures an Invalidator.
+type InvalidatorOption[K comparable, V any] func(*Invalidator[K, V])
+
+// WithReplication makes Put publish the written values, encoded with the given codec, so the other replicas store
+// them instead of only invalidating the keys. It is meant for hot keys that every replica is going to read.
+func WithReplication[K comparable, V any](values Codec[V]) InvalidatorOption[K, V] {
+	return func(i *Invalidator[K, V]) {
+		i.values = values
+	}
+}
+
+// NewInvalidator creates a new invalidator for the given cache, publishing to and subscribed to the given channel. The
+// invalidator must be closed with Close once it is no longer used.
+func NewInvalidator[K comparable, V any](cache *InMemoryCache[K, V], pubsub PubSub, keys KeyCodec[K], opts ...InvalidatorOption[K, V]) (*Invalidator[K, V], error) {
+	origin := make([]byte, 8)
+	if _, err := rand.Read(origin); err != nil {
+		return nil, err
+	}
+	i := &Invalidator[K, V]{cache: cache, pubsub: pubsub, keys: keys, origin: hex.EncodeToString(origin)}
+	for _, opt := range opts {
+		opt(i)
+	}
+	unsubscribe, err := pubsub.Subscribe(i.receive)
+	if err != nil {
+		return nil, fmt.Errorf("ugulru: subscribe to invalidations: %w", err)
+	}
+	i.unsubscribe = unsubscribe
+	return i, nil
+}
+
+// Remove removes the given keys from the cache and publishes their invalidation to the other replicas. The keys are
+// removed locally even if publishing fails. If a key can't be encoded, nothing is removed or published.
+func (i *Invalidator[K, V]) Remove(ctx context.Context, keys ...K) error {
+	msg := invalidation{Origin: i.origin, Keys: make([]string, len(keys))}
+	for n, key := range keys {
+		encoded, err := i.keys.EncodeKey(key)
+		if err != nil {
+			return err
+		}
+		msg.Keys[n] = encoded
+	}
+	for _, key := range keys {
+		i.cache.Remove(key)
+	}
+	return i.publish(ctx, msg)
+}
+
+// Put stores the value for the given key in the cache and invalidates the key in the other replicas, or replicates the
+// value to them with WithReplication. The value is stored locally even if publishing fails.
+func (i *Invalidator[K, V]) Put(ctx context.Context, key K, value V) error {
+	i.cache.Put(key, value)
+	encoded, err := i.keys.EncodeKey(key)
+	if err != nil {
+		return err
+	}
+	msg := invalidation{Origin: i.origin, Keys: []string{encoded}}
+	if i.values != nil {
+		data, err := i.values.Encode(value)
+		if err != nil {
+			return err
+		}
+		msg.Values = [][]byte{data}
+	}
+	return i.publish(ctx, msg)
+}
+
+// Clear removes all the entries from the cache and publishes the invalidation to the other replicas. The cache is
+// cleared locally even if publishing fails.
+func (i *Invalidator[K, V]) Clear(ctx context.Context) error {
+	i.clear()
+	return i.publish(ctx, invalidation{Origin: i.origin, All: true})
+}
+
+// Close stops applying the invalidations published by other replicas.
+func (i *Invalidator[K, V]) Close() error {
+	return i.unsubscribe()
+}
+
+// publish publishes the given invalidation.
+func (i *Invalidator[K, V]) publish(ctx context.Context, msg invalidation) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if err := i.pubsub.Publish(ctx, payload); err != nil {
+		return fmt.Errorf("ugulru: publish invalidation: %w", err)
+	}
+	return nil
+}
+
+// receive applies an invalidation published by another replica to the cache only: the store set by WithWriteThrough,
+// if any, is shared by the replicas and was already updated by the publisher. Malformed payloads and keys are ignored,
+// and keys whose replicated value can't be decoded are invalidated.
+func (i *Invalidator[K, V]) receive(payload []byte) {
+	var msg invalidation
+	if err := json.Unmarshal(payload, &msg); err != nil || msg.Origin == i.origin {
+		return
+	}
+	if msg.All {
+		i.clear()
+		return
+	}
+	for n, encoded := range msg.Keys {
+		key, err := i.keys.DecodeKey(encoded)
+		if err != nil {
+			continue
+		}
+		if n < len(msg.Values) && i.values != nil {
+			if value, err := i.values.Decode(msg.Values[n]); err == nil {
+				i.cache.replicate(key, value)
+				continue
+			}
+		}
+		i.cache.discard(key)
+	}
+}
+
+// replicate stores the value for the given key in the cache but not in the store, e.g. to apply a write made by
+// another replica sharing the store.
+func (c *InMemoryCache[K, V]) replicate(key K, value V) {
+	defer c.unlock(c.lock())
+
+	c.store(key, value, nil)
+}
+
+// clear removes all the entries from the cache, but not from the store set by WithWriteThrough, which other replicas
+// share.
+func (i *Invalidator[K, V]) clear() {
+	i.cache.discardAll()
+}
@@ -0,0 +1,204 @@
+package ugulru_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryPubSub is a PubSub delivering payloads synchronously to the subscribers in the same process, for tests.
+type memoryPubSub struct {
+	mu       sync.Mutex
+	handlers map[int]func([]byte)
+	next     int
+	err      error
+}
+
+func (p *memoryPubSub) Publish(ctx context.Context, payload []byte) error {
+	p.mu.Lock()
+	if p.err != nil {
+		p.mu.Unlock()
+		return p.err
+	}
+	handlers := make([]func([]byte), 0, len(p.handlers))
+	for _, handler := range p.handlers {
+		handlers = append(handlers, handler)
+	}
+	p.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(payload)
+	}
+	return nil
+}
+
+func (p *memoryPubSub) Subscribe(handler func([]byte)) (func() error, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.handlers == nil {
+		p.handlers = make(map[int]func([]byte))
+	}
+	id := p.next
+	p.next++
+	p.handlers[id] = handler
+	return func() error {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		delete(p.handlers, id)
+		return nil
+	}, nil
+}
+
+func TestInvalidator(t *testing.T) {
+	ctx := context.Background()
+	pubsub := &memoryPubSub{}
+	caches := make([]*ugulru.InMemoryCache[string, int], 3)
+	invalidators := make([]*ugulru.Invalidator[string, int], 3)
+	for i := range caches {
+		caches[i] = ugulru.NewInMemoryCache[string, int](10, time.Minute)
+		caches[i].Put("key1", 1)
+		caches[i].Put("key2", 2)
+		caches[i].Put("key3", 3)
+
+		var err error
+		invalidators[i], err = ugulru.NewInvalidator(caches[i], pubsub, ugulru.StringKeyCodec[string]{})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, invalidators[0].Remove(ctx, "key1", "key2"))
+	for _, cache := range caches {
+		_, ok := cache.Get("key1")
+		assert.False(t, ok)
+		_, ok = cache.Get("key2")
+		assert.False(t, ok)
+		_, ok = cache.Get("key3")
+		assert.True(t, ok)
+	}
+
+	// A closed invalidator no longer applies remote invalidations
+	require.NoError(t, invalidators[2].Close())
+	require.NoError(t, invalidators[1].Clear(ctx))
+	_, ok := caches[0].Get("key3")
+	assert.False(t, ok)
+	_, ok = caches[1].Get("key3")
+	assert.False(t, ok)
+	_, ok = caches[2].Get("key3")
+	assert.True(t, ok)
+
+	// Keys are removed locally even if publishing fails
+	caches[0].Put("key4", 4)
+	caches[1].Put("key4", 4)
+	pubsub.err = errors.New("broker is down")
+	assert.ErrorIs(t, invalidators[0].Remove(ctx, "key4"), pubsub.err)
+	_, ok = caches[0].Get("key4")
+	assert.False(t, ok)
+	_, ok = caches[1].Get("key4")
+	assert.True(t, ok)
+}
+
+func TestWithReplication(t *testing.T) {
+	ctx := context.Background()
+	pubsub := &memoryPubSub{}
+	local := ugulru.NewInMemoryCache[string, int](10, time.Minute)
+	remote := ugulru.NewInMemoryCache[string, int](10, time.Minute)
+	plain := ugulru.NewInMemoryCache[string, int](10, time.Minute)
+	plain.Put("key1", 0)
+
+	localInvalidator, err := ugulru.NewInvalidator(local, pubsub, ugulru.StringKeyCodec[string]{},
+		ugulru.WithReplication[string, int](ugulru.JSONCodec[int]{}))
+	require.NoError(t, err)
+	_, err = ugulru.NewInvalidator(remote, pubsub, ugulru.StringKeyCodec[string]{},
+		ugulru.WithReplication[string, int](ugulru.JSONCodec[int]{}))
+	require.NoError(t, err)
+	_, err = ugulru.NewInvalidator(plain, pubsub, ugulru.StringKeyCodec[string]{})
+	require.NoError(t, err)
+
+	require.NoError(t, localInvalidator.Put(ctx, "key1", 1))
+	value, ok := local.Get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	value, ok = remote.Get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	// Replicas without a codec only invalidate the key
+	_, ok = plain.Get("key1")
+	assert.False(t, ok)
+}
+
+func TestInvalidator_SharedStore(t *testing.T) {
+	ctx := context.Background()
+	pubsub := &memoryPubSub{}
+	store := newMapStore[string, int]()
+	newReplica := func(opts ...ugulru.InvalidatorOption[string, int]) (*ugulru.InMemoryCache[string, int], *ugulru.Invalidator[string, int]) {
+		cache := ugulru.NewInMemoryCache(10, time.Minute, ugulru.WithWriteThrough[string, int](store))
+		invalidator, err := ugulru.NewInvalidator(cache, pubsub, ugulru.StringKeyCodec[string]{}, opts...)
+		require.NoError(t, err)
+		t.Cleanup(func() { invalidator.Close() })
+		return cache, invalidator
+	}
+	_, a := newReplica()
+	b, _ := newReplica()
+	c, _ := newReplica(ugulru.WithReplication[string, int](ugulru.GobCodec[int]{}))
+
+	// Invalidations received by the other replicas don't delete the value from the shared store
+	b.Put("k", 1)
+	require.NoError(t, a.Put(ctx, "k", 42))
+	assert.Equal(t, map[string]int{"k": 42}, store.data)
+	value, err := b.Load("k", func() (int, error) {
+		return 0, errors.New("not found")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 42, value, "b should read the new value from the store")
+
+	require.NoError(t, a.Clear(ctx))
+	assert.Equal(t, map[string]int{"k": 42}, store.data)
+	_, ok := c.Get("k")
+	assert.False(t, ok)
+}
+
+// failingKeyCodec is a StringKeyCodec that fails to encode the key "bad".
+type failingKeyCodec struct {
+	ugulru.StringKeyCodec[string]
+}
+
+func (c failingKeyCodec) EncodeKey(key string) (string, error) {
+	if key == "bad" {
+		return "", errors.New("bad key")
+	}
+	return c.StringKeyCodec.EncodeKey(key)
+}
+
+func TestInvalidator_RemoveEncodeError(t *testing.T) {
+	pubsub := &memoryPubSub{}
+	local := ugulru.NewInMemoryCache[string, int](10, time.Minute)
+	remote := ugulru.NewInMemoryCache[string, int](10, time.Minute)
+	invalidator, err := ugulru.NewInvalidator(local, pubsub, failingKeyCodec{})
+	require.NoError(t, err)
+	defer invalidator.Close()
+	other, err := ugulru.NewInvalidator(remote, pubsub, failingKeyCodec{})
+	require.NoError(t, err)
+	defer other.Close()
+
+	for _, cache := range []*ugulru.InMemoryCache[string, int]{local, remote} {
+		cache.Put("a", 1)
+		cache.Put("b", 2)
+	}
+	assert.Error(t, invalidator.Remove(context.Background(), "a", "bad", "b"))
+
+	// Nothing is removed locally without being published, so the replicas stay coherent
+	for _, cache := range []*ugulru.InMemoryCache[string, int]{local, remote} {
+		_, ok := cache.Get("a")
+		assert.True(t, ok)
+		_, ok = cache.Get("b")
+		assert.True(t, ok)
+	}
+}
@@ -0,0 +1,32 @@
+package ugulru
+
+import "iter"
+
+// All returns an iterator over the live key-value pairs of the cache, from the most to the least recently used.
+// The cache is locked while the iteration is in progress, so the loop body must not call any methods of the cache.
+func (c *InMemoryCache[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		c.Range(yield)
+	}
+}
+
+// Keys returns an iterator over the keys of the live entries of the cache, from the most to the least recently used.
+// The cache is locked while the iteration is in progress, so the loop body must not call any methods of the cache.
+func (c *InMemoryCache[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		c.Range(func(key K, _ V) bool {
+			return yield(key)
+		})
+	}
+}
+
+// Values returns an iterator over the values of the live entries of the cache, from the most to the least recently
+// used. The cache is locked while the iteration is in progress, so the loop body must not call any methods of the
+// cache.
+func (c *InMemoryCache[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		c.Range(func(_ K, value V) bool {
+			return yield(value)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package ugulru_test
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryCache_All(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+	cache.Put("key3", 3)
+
+	assert.Equal(t, map[string]int{"key1": 1, "key2": 2, "key3": 3}, maps.Collect(cache.All()))
+
+	// Test breaking out of the loop
+	var visited int
+	for range cache.All() {
+		visited++
+		break
+	}
+	assert.Equal(t, 1, visited)
+}
+
+func TestInMemoryCache_Keys(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+	cache.Put("key3", 3)
+
+	assert.Equal(t, []string{"key3", "key2", "key1"}, slices.Collect(cache.Keys()))
+}
+
+func TestInMemoryCache_Values(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+	cache.Put("key3", 3)
+
+	assert.Equal(t, []int{1, 2, 3}, slices.Sorted(cache.Values()))
+}
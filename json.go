@@ -0,0 +1,54 @@
+package ugulru
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// jsonEntry is a single entry of the JSON representation of a cache.
+type jsonEntry[K comparable, V any] struct {
+	Key     K         `json:"key"`
+	Value   V         `json:"value"`
+	Expires time.Time `json:"expires"`
+}
+
+// MarshalJSON encodes the live entries of the cache as a JSON array of objects with the key, the value and the time
+// the entry expires, from the most to the least recently used, so the cache can be embedded in debug dumps and
+// golden-file tests. Negative entries are not encoded.
+func (c *InMemoryCache[K, V]) MarshalJSON() ([]byte, error) {
+	entries := c.snapshot()
+	out := make([]jsonEntry[K, V], len(entries))
+	for i, e := range entries {
+		out[i] = jsonEntry[K, V](e)
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes entries encoded by MarshalJSON and writes them to the cache like Restore does: expired entries
+// are skipped, only the most recently used ones are kept if there are more entries than the capacity of the cache,
+// and entries already in the cache are kept unless they are overwritten or evicted. The cache must have been created
+// with NewInMemoryCache.
+func (c *InMemoryCache[K, V]) UnmarshalJSON(data []byte) error {
+	var in []jsonEntry[K, V]
+	if err := json.Unmarshal(data, &in); err != nil {
+		return fmt.Errorf("ugulru: unmarshal cache: %w", err)
+	}
+
+	c.mu.Lock()
+	capacity := c.capacity
+	c.mu.Unlock()
+
+	now := time.Now()
+	entries := make([]snapshotEntry[K, V], 0, min(len(in), capacity))
+	for _, e := range in {
+		if len(entries) == capacity {
+			break
+		}
+		if e.Expires.After(now) {
+			entries = append(entries, snapshotEntry[K, V](e))
+		}
+	}
+	c.restoreAll(entries)
+	return nil
+}
@@ -0,0 +1,63 @@
+package ugulru_test
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryCache_MarshalJSON(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+	cache.PutWithTTL("expired", 3, 0, time.Nanosecond)
+	time.Sleep(2 * time.Millisecond)
+
+	data, err := json.Marshal(cache)
+	require.NoError(t, err)
+
+	var entries []struct {
+		Key     string    `json:"key"`
+		Value   int       `json:"value"`
+		Expires time.Time `json:"expires"`
+	}
+	require.NoError(t, json.Unmarshal(data, &entries))
+	require.Len(t, entries, 2)
+	assert.Equal(t, "key2", entries[0].Key)
+	assert.Equal(t, 2, entries[0].Value)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), entries[0].Expires, time.Second)
+	assert.Equal(t, "key1", entries[1].Key)
+}
+
+func TestInMemoryCache_UnmarshalJSON(t *testing.T) {
+	now := time.Now()
+	data := []byte(`[
+		{"key": "key4", "value": 4, "expires": "` + now.Add(time.Minute).Format(time.RFC3339Nano) + `"},
+		{"key": "expired", "value": 0, "expires": "` + now.Add(-time.Minute).Format(time.RFC3339Nano) + `"},
+		{"key": "key3", "value": 3, "expires": "` + now.Add(time.Hour).Format(time.RFC3339Nano) + `"},
+		{"key": "key2", "value": 2, "expires": "` + now.Add(time.Hour).Format(time.RFC3339Nano) + `"},
+		{"key": "key1", "value": 1, "expires": "` + now.Add(time.Hour).Format(time.RFC3339Nano) + `"}
+	]`)
+
+	cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+	require.NoError(t, json.Unmarshal(data, cache))
+	assert.Equal(t, []string{"key4", "key3", "key2"}, slices.Collect(cache.Keys()))
+
+	_, expiry, ok := cache.GetWithExpiry("key4")
+	assert.True(t, ok)
+	assert.WithinDuration(t, now.Add(time.Minute), expiry, time.Second)
+
+	// Round trip
+	encoded, err := json.Marshal(cache)
+	require.NoError(t, err)
+	restored := ugulru.NewInMemoryCache[string, int](3, time.Hour)
+	require.NoError(t, json.Unmarshal(encoded, restored))
+	assert.Equal(t, slices.Collect(cache.Keys()), slices.Collect(restored.Keys()))
+
+	assert.Error(t, json.Unmarshal([]byte(`{}`), cache))
+}
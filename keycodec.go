@@ -0,0 +1,94 @@
+package ugulru
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// KeyCodec converts keys to and from the stable string representation used by remote tiers such as Redis, memcached or
+// disk stores. Encoding the same key must always produce the same string.
+type KeyCodec[K any] interface {
+	EncodeKey(key K) (string, error)
+	DecodeKey(s string) (K, error)
+}
+
+// StringKeyCodec is a KeyCodec for string keys, which are used as is.
+type StringKeyCodec[K ~string] struct{}
+
+func (StringKeyCodec[K]) EncodeKey(key K) (string, error) {
+	return string(key), nil
+}
+
+func (StringKeyCodec[K]) DecodeKey(s string) (K, error) {
+	return K(s), nil
+}
+
+// integer is a constraint that permits any integer type.
+type integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// IntKeyCodec is a KeyCodec for integer keys, which are encoded in decimal.
+type IntKeyCodec[K integer] struct{}
+
+func (IntKeyCodec[K]) EncodeKey(key K) (string, error) {
+	if key < 0 {
+		return strconv.FormatInt(int64(key), 10), nil
+	}
+	return strconv.FormatUint(uint64(key), 10), nil
+}
+
+func (IntKeyCodec[K]) DecodeKey(s string) (K, error) {
+	if strings.HasPrefix(s, "-") {
+		n, err := strconv.ParseInt(s, 10, 64)
+		return K(n), err
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	return K(n), err
+}
+
+// JSONKeyCodec is a KeyCodec for composite keys, such as structs, which are encoded as JSON. Struct fields are encoded
+// in declaration order and map keys are sorted, so the encoding is stable.
+type JSONKeyCodec[K any] struct{}
+
+func (JSONKeyCodec[K]) EncodeKey(key K) (string, error) {
+	b, err := json.Marshal(key)
+	if err != nil {
+		return "", fmt.Errorf("ugulru: encode key: %w", err)
+	}
+	return string(b), nil
+}
+
+func (JSONKeyCodec[K]) DecodeKey(s string) (K, error) {
+	var key K
+	if err := json.Unmarshal([]byte(s), &key); err != nil {
+		return key, fmt.Errorf("ugulru: decode key: %w", err)
+	}
+	return key, nil
+}
+
+// PrefixKeyCodec wraps another KeyCodec and prefixes the encoded keys, so several caches can share a remote tier
+// without key collisions.
+type PrefixKeyCodec[K any] struct {
+	Prefix string
+	Codec  KeyCodec[K]
+}
+
+func (c PrefixKeyCodec[K]) EncodeKey(key K) (string, error) {
+	s, err := c.Codec.EncodeKey(key)
+	if err != nil {
+		return "", err
+	}
+	return c.Prefix + s, nil
+}
+
+func (c PrefixKeyCodec[K]) DecodeKey(s string) (K, error) {
+	rest, ok := strings.CutPrefix(s, c.Prefix)
+	if !ok {
+		var zero K
+		return zero, fmt.Errorf("ugulru: decode key: %q doesn't have prefix %q", s, c.Prefix)
+	}
+	return c.Codec.DecodeKey(rest)
+}
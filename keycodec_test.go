@@ -0,0 +1,45 @@
+package ugulru_test
+
+import (
+	"testing"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func testKeyCodec[K any](t *testing.T, codec ugulru.KeyCodec[K], key K, encoded string) {
+	t.Helper()
+
+	s, err := codec.EncodeKey(key)
+	assert.NoError(t, err)
+	assert.Equal(t, encoded, s)
+
+	decoded, err := codec.DecodeKey(s)
+	assert.NoError(t, err)
+	assert.Equal(t, key, decoded)
+}
+
+func TestKeyCodecs(t *testing.T) {
+	type userID string
+	type compositeKey struct {
+		Tenant string
+		ID     int
+	}
+
+	testKeyCodec[userID](t, ugulru.StringKeyCodec[userID]{}, "u1", "u1")
+	testKeyCodec[int](t, ugulru.IntKeyCodec[int]{}, -42, "-42")
+	testKeyCodec[uint64](t, ugulru.IntKeyCodec[uint64]{}, 1<<63, "9223372036854775808")
+	testKeyCodec(t, ugulru.JSONKeyCodec[compositeKey]{}, compositeKey{Tenant: "t1", ID: 7}, `{"Tenant":"t1","ID":7}`)
+	testKeyCodec[int](t, ugulru.PrefixKeyCodec[int]{Prefix: "users:", Codec: ugulru.IntKeyCodec[int]{}}, 7, "users:7")
+}
+
+func TestKeyCodecs_DecodeErrors(t *testing.T) {
+	_, err := ugulru.IntKeyCodec[int]{}.DecodeKey("abc")
+	assert.Error(t, err)
+
+	_, err = ugulru.JSONKeyCodec[struct{ ID int }]{}.DecodeKey("{")
+	assert.Error(t, err)
+
+	_, err = ugulru.PrefixKeyCodec[string]{Prefix: "a:", Codec: ugulru.StringKeyCodec[string]{}}.DecodeKey("b:1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,29 @@
+package ugulru
+
+// WithLFUEviction makes the cache evict the least frequently used entry instead of the least recently used one, so
+// periodic scans over many keys can't wipe out the hot keys. Every read and write of an entry counts as an access;
+// combine with WithFrequencyHalfLife so keys that were hot in the past don't stay resident forever. Among entries with
+// the same frequency, the least recently used one is evicted. Finding the victim takes time proportional to the number
+// of entries.
+func WithLFUEviction[K comparable, V any]() Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.lfu = true
+	}
+}
+
+// leastFrequent returns the least recently used unpinned entry among those with the lowest access frequency, or nil
+// if all the entries are pinned. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) leastFrequent() *entry[K, V] {
+	victim := c.evictable(c.list.Back())
+	if victim == nil {
+		return nil
+	}
+	now := c.clock()
+	freq := c.frequency(victim, now)
+	for e := c.evictable(victim.Prev()); e != nil; e = c.evictable(e.Prev()) {
+		if f := c.frequency(e, now); f < freq {
+			victim, freq = e, f
+		}
+	}
+	return victim
+}
@@ -0,0 +1,35 @@
+package ugulru_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLFUEviction(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(3, 5*time.Minute, ugulru.WithLFUEviction[string, int]())
+
+	cache.Put("hot1", 1)
+	cache.Put("hot2", 2)
+	for range 3 {
+		cache.Get("hot1")
+		cache.Get("hot2")
+	}
+
+	// A scan only ever evicts the keys it brought in
+	for i := range 10 {
+		cache.Put(fmt.Sprintf("scan%d", i), i)
+	}
+
+	_, ok := cache.Get("hot1")
+	assert.True(t, ok)
+	_, ok = cache.Get("hot2")
+	assert.True(t, ok)
+	_, ok = cache.Get("scan9")
+	assert.True(t, ok)
+	_, ok = cache.Get("scan8")
+	assert.False(t, ok)
+}
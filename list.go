@@ -0,0 +1,111 @@
+package ugulru
+
+// linkedList is an intrusive doubly linked list, used for the entries of InMemoryCache ordered from the most to the
+// least recently used, and for the queues of the other caches. Unlike container/list, the links live in the elements
+// themselves, which embed listLinks, so adding an element allocates nothing and walking the list needs no type
+// assertions. A list must be initialized with init and must not be copied.
+type linkedList[T any, P listNode[T]] struct {
+	root T // sentinel: its next link is the front and its prev link the back of the list
+	len  int
+}
+
+// listNode is satisfied by pointers to the elements of a linkedList.
+type listNode[T any] interface {
+	*T
+	links() *listLinks[T]
+}
+
+// listLinks holds the links of an element of a linkedList. Embedding it makes a type usable as an element.
+type listLinks[T any] struct {
+	next, prev *T
+	root       *T // sentinel of the list holding the element, nil if it is in no list
+}
+
+func (n *listLinks[T]) links() *listLinks[T] {
+	return n
+}
+
+// Next returns the next element of the list, towards the back, or nil.
+func (n *listLinks[T]) Next() *T {
+	if n.root == nil || n.next == n.root {
+		return nil
+	}
+	return n.next
+}
+
+// Prev returns the previous element of the list, towards the front, or nil.
+func (n *listLinks[T]) Prev() *T {
+	if n.root == nil || n.prev == n.root {
+		return nil
+	}
+	return n.prev
+}
+
+// init initializes or clears the list.
+func (l *linkedList[T, P]) init() {
+	root := P(&l.root).links()
+	root.next = &l.root
+	root.prev = &l.root
+	l.len = 0
+}
+
+// Len returns the number of elements in the list.
+func (l *linkedList[T, P]) Len() int {
+	return l.len
+}
+
+// Front returns the first element of the list, or nil if the list is empty.
+func (l *linkedList[T, P]) Front() *T {
+	if l.len == 0 {
+		return nil
+	}
+	return P(&l.root).links().next
+}
+
+// Back returns the last element of the list, or nil if the list is empty.
+func (l *linkedList[T, P]) Back() *T {
+	if l.len == 0 {
+		return nil
+	}
+	return P(&l.root).links().prev
+}
+
+// PushFront inserts the given element at the front of the list and returns it.
+func (l *linkedList[T, P]) PushFront(e *T) *T {
+	l.insertAfter(e, &l.root)
+	l.len++
+	return e
+}
+
+// MoveToFront moves the given element of the list to the front of the list.
+func (l *linkedList[T, P]) MoveToFront(e *T) {
+	if P(&l.root).links().next == e {
+		return
+	}
+	l.unlink(e)
+	l.insertAfter(e, &l.root)
+}
+
+// Remove removes the given element from the list if it is in the list.
+func (l *linkedList[T, P]) Remove(e *T) {
+	n := P(e).links()
+	if n.root != &l.root {
+		return
+	}
+	l.unlink(e)
+	n.next, n.prev, n.root = nil, nil, nil
+	l.len--
+}
+
+func (l *linkedList[T, P]) insertAfter(e, at *T) {
+	n, a := P(e).links(), P(at).links()
+	n.prev, n.next, n.root = at, a.next, &l.root
+	P(a.next).links().prev = e
+	a.next = e
+}
+
+func (l *linkedList[T, P]) unlink(e *T) {
+	n := P(e).links()
+	P(n.prev).links().next = n.next
+	P(n.next).links().prev = n.prev
+}
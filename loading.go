@@ -0,0 +1,41 @@
+package ugulru
+
+import (
+	"context"
+	"time"
+)
+
+// LoadingCache is an InMemoryCache with a loader function of its own, so Get transparently loads missing keys and
+// call sites don't have to pass a loader to every call.
+type LoadingCache[K comparable, V any] struct {
+	*InMemoryCache[K, V]
+	loader func(ctx context.Context, key K) (V, error)
+}
+
+// NewLoadingCache creates a new loading cache with the specified capacity, TTL duration and loader function.
+func NewLoadingCache[K comparable, V any](
+	capacity int,
+	ttl time.Duration,
+	loader func(ctx context.Context, key K) (V, error),
+	opts ...Option[K, V],
+) *LoadingCache[K, V] {
+	return &LoadingCache[K, V]{
+		InMemoryCache: NewInMemoryCache(capacity, ttl, opts...),
+		loader:        loader,
+	}
+}
+
+// Get retrieves a value from the cache based on the given key. If the key doesn't exist in the cache or has expired,
+// the value is loaded with the loader function of the cache, stored in the cache and returned. Concurrent calls for the
+// same key share a single load, as with LoadContext.
+func (c *LoadingCache[K, V]) Get(ctx context.Context, key K) (V, error) {
+	return c.LoadContext(ctx, key, func(ctx context.Context) (V, error) {
+		return c.loader(ctx, key)
+	})
+}
+
+// GetIfPresent retrieves a value from the cache based on the given key without loading it if it is missing. It returns
+// the value and a boolean indicating whether the key exists in the cache.
+func (c *LoadingCache[K, V]) GetIfPresent(key K) (V, bool) {
+	return c.InMemoryCache.Get(key)
+}
@@ -0,0 +1,48 @@
+package ugulru_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadingCache(t *testing.T) {
+	errNotFound := errors.New("not found")
+	calls := 0
+	cache := ugulru.NewLoadingCache(2, 5*time.Minute, func(ctx context.Context, key string) (int, error) {
+		calls++
+		if key == "missing" {
+			return 0, errNotFound
+		}
+		return len(key), nil
+	})
+
+	_, ok := cache.GetIfPresent("key1")
+	assert.False(t, ok)
+
+	value, err := cache.Get(context.Background(), "key1")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, value)
+
+	value, err = cache.Get(context.Background(), "key1")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, value)
+	assert.Equal(t, 1, calls)
+
+	value, ok = cache.GetIfPresent("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 4, value)
+
+	_, err = cache.Get(context.Background(), "missing")
+	assert.ErrorIs(t, err, errNotFound)
+
+	// Methods of the underlying cache are available
+	cache.Put("key2", 10)
+	value, err = cache.Get(context.Background(), "key2")
+	assert.NoError(t, err)
+	assert.Equal(t, 10, value)
+}
@@ -0,0 +1,194 @@
+// Package memcachedcache provides a ugulru.Cache backed by a fleet of memcached servers, with keys spread across the
+// servers by consistent hashing so adding or removing a server only moves the keys of that server.
+package memcachedcache
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/machine23/ugulru"
+	"github.com/machine23/ugulru/internal/ring"
+)
+
+// DefaultReplicas is the number of points each server is placed at on the hash ring of Servers.
+const DefaultReplicas = 160
+
+// Servers is a memcache.ServerSelector spreading keys across servers by consistent hashing. Use it with
+// memcache.NewFromSelector. It is safe for concurrent use, and servers can be added and removed while in use.
+type Servers struct {
+	mu   sync.RWMutex
+	ring *ring.Ring[net.Addr]
+}
+
+var _ memcache.ServerSelector = (*Servers)(nil)
+
+// NewServers creates a new server selector for the servers with the given addresses, in the host:port form, or paths
+// of Unix sockets.
+func NewServers(addrs ...string) (*Servers, error) {
+	s := &Servers{ring: ring.New[net.Addr](DefaultReplicas)}
+	for _, addr := range addrs {
+		if err := s.Add(addr); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Add adds the server with the given address.
+func (s *Servers) Add(addr string) error {
+	resolved, err := resolve(addr)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ring.Add(addr, resolved)
+	return nil
+}
+
+// Remove removes the server with the given address. Its keys move to the remaining servers.
+func (s *Servers) Remove(addr string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ring.Remove(addr)
+}
+
+// PickServer returns the address of the server the given key belongs to.
+func (s *Servers) PickServer(key string) (net.Addr, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	addr, ok := s.ring.Get(key)
+	if !ok {
+		return nil, memcache.ErrNoServers
+	}
+	return addr, nil
+}
+
+// Each calls fn for every server, stopping at the first error.
+func (s *Servers) Each(fn func(net.Addr) error) error {
+	s.mu.RLock()
+	addrs := s.ring.Nodes()
+	s.mu.RUnlock()
+
+	for _, addr := range addrs {
+		if err := fn(addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve resolves the given server address, which is a Unix socket if it contains a slash.
+func resolve(addr string) (net.Addr, error) {
+	if strings.Contains(addr, "/") {
+		return net.ResolveUnixAddr("unix", addr)
+	}
+	return net.ResolveTCPAddr("tcp", addr)
+}
+
+// Cache is a ugulru.Cache storing its entries in memcached. Keys and values are converted to strings and bytes with
+// the given codecs; encoded keys must be valid memcached keys, at most 250 bytes without spaces or control characters.
+// Memcached errors are treated as misses by Get and Load, and Put and Remove drop them. It is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	client *memcache.Client
+	ttl    time.Duration
+	keys   ugulru.KeyCodec[K]
+	values ugulru.Codec[V]
+}
+
+var _ ugulru.Cache[string, any] = (*Cache[string, any])(nil)
+
+// New creates a new cache storing its entries in memcached through the given client with the given time-to-live,
+// which memcached rounds to whole seconds. A TTL of zero means that entries never expire, although memcached may still
+// evict them. The client is owned by the caller, who must close it once the cache is no longer used.
+func New[K comparable, V any](client *memcache.Client, ttl time.Duration, keys ugulru.KeyCodec[K], values ugulru.Codec[V]) *Cache[K, V] {
+	return &Cache[K, V]{client: client, ttl: ttl, keys: keys, values: values}
+}
+
+// Get retrieves the value for the given key, if it exists and hasn't expired.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	value, err := c.get(key)
+	return value, err == nil
+}
+
+// get reads and decodes the value for the given key. It returns memcache.ErrCacheMiss if the key doesn't exist.
+func (c *Cache[K, V]) get(key K) (V, error) {
+	var value V
+	k, err := c.keys.EncodeKey(key)
+	if err != nil {
+		return value, err
+	}
+	item, err := c.client.Get(k)
+	if err != nil {
+		return value, err
+	}
+	return c.values.Decode(item.Value)
+}
+
+// Put stores the value for the given key with the TTL of the cache.
+func (c *Cache[K, V]) Put(key K, value V) {
+	_ = c.put(key, value)
+}
+
+// put encodes and writes the value for the given key.
+func (c *Cache[K, V]) put(key K, value V) error {
+	k, err := c.keys.EncodeKey(key)
+	if err != nil {
+		return err
+	}
+	data, err := c.values.Encode(value)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(&memcache.Item{Key: k, Value: data, Expiration: c.expiration()})
+}
+
+// maxRelativeExpiration is the longest expiration memcached accepts as relative to now. Longer ones are interpreted
+// as Unix times.
+const maxRelativeExpiration = 30 * 24 * time.Hour
+
+// expiration returns the memcached expiration of an entry written now.
+func (c *Cache[K, V]) expiration() int32 {
+	switch {
+	case c.ttl <= 0:
+		return 0
+	case c.ttl > maxRelativeExpiration:
+		return int32(time.Now().Add(c.ttl).Unix())
+	}
+	// Round up, since zero means no expiry
+	return int32((c.ttl + time.Second - 1) / time.Second)
+}
+
+// Remove deletes the entry with the given key.
+func (c *Cache[K, V]) Remove(key K) {
+	k, err := c.keys.EncodeKey(key)
+	if err != nil {
+		return
+	}
+	_ = c.client.Delete(k)
+}
+
+// RemoveExpired does nothing, since memcached removes expired keys on its own.
+func (c *Cache[K, V]) RemoveExpired() {}
+
+// Load retrieves the value for the given key. If the key doesn't exist or has expired, the loader is called to load
+// the value, which is then stored in memcached and returned. The loaded value is returned even if it can't be stored.
+// Concurrent loads of the same key, in this process or others, are not deduplicated.
+func (c *Cache[K, V]) Load(key K, loader func() (V, error)) (V, error) {
+	if value, err := c.get(key); err == nil {
+		return value, nil
+	}
+	value, err := loader()
+	if err != nil {
+		return value, err
+	}
+	_ = c.put(key, value)
+	return value, nil
+}
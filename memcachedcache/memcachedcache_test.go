@@ -0,0 +1,165 @@
+package memcachedcache_test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/machine23/ugulru"
+	"github.com/machine23/ugulru/memcachedcache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// server is a fake memcached server supporting the commands used by the cache.
+type server struct {
+	mu    sync.Mutex
+	items map[string][]byte
+	exps  map[string]int32
+}
+
+func startServer(t *testing.T) (*server, string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		ln.Close()
+	})
+
+	s := &server{items: make(map[string][]byte), exps: make(map[string]int32)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s, ln.Addr().String()
+}
+
+func (s *server) serve(conn net.Conn) {
+	defer conn.Close()
+
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+	for {
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		s.mu.Lock()
+		switch fields[0] {
+		case "gets":
+			for _, key := range fields[1:] {
+				if value, ok := s.items[key]; ok {
+					fmt.Fprintf(rw, "VALUE %s 0 %d 1\r\n%s\r\n", key, len(value), value)
+				}
+			}
+			fmt.Fprint(rw, "END\r\n")
+		case "set":
+			size, _ := strconv.Atoi(fields[4])
+			exp, _ := strconv.Atoi(fields[3])
+			value := make([]byte, size+2)
+			io.ReadFull(rw, value)
+			s.items[fields[1]] = value[:size]
+			s.exps[fields[1]] = int32(exp)
+			fmt.Fprint(rw, "STORED\r\n")
+		case "delete":
+			if _, ok := s.items[fields[1]]; ok {
+				delete(s.items, fields[1])
+				fmt.Fprint(rw, "DELETED\r\n")
+			} else {
+				fmt.Fprint(rw, "NOT_FOUND\r\n")
+			}
+		default:
+			fmt.Fprint(rw, "ERROR\r\n")
+		}
+		s.mu.Unlock()
+		rw.Flush()
+	}
+}
+
+func (s *server) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.items)
+}
+
+func TestCache(t *testing.T) {
+	srv, addr := startServer(t)
+	servers, err := memcachedcache.NewServers(addr)
+	require.NoError(t, err)
+	cache := memcachedcache.New[string, int](memcache.NewFromSelector(servers), 1500*time.Millisecond,
+		ugulru.StringKeyCodec[string]{}, ugulru.JSONCodec[int]{})
+
+	_, ok := cache.Get("key1")
+	assert.False(t, ok)
+
+	cache.Put("key1", 1)
+	value, ok := cache.Get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	srv.mu.Lock()
+	assert.Equal(t, int32(2), srv.exps["key1"], "the TTL should be rounded up to seconds")
+	srv.mu.Unlock()
+
+	cache.Remove("key1")
+	_, ok = cache.Get("key1")
+	assert.False(t, ok)
+
+	loads := 0
+	loader := func() (int, error) {
+		loads++
+		return 2, nil
+	}
+	for range 2 {
+		value, err := cache.Load("key2", loader)
+		require.NoError(t, err)
+		assert.Equal(t, 2, value)
+	}
+	assert.Equal(t, 1, loads)
+}
+
+func TestServers(t *testing.T) {
+	srvs := make([]*server, 3)
+	addrs := make([]string, 3)
+	for i := range srvs {
+		srvs[i], addrs[i] = startServer(t)
+	}
+	servers, err := memcachedcache.NewServers(addrs...)
+	require.NoError(t, err)
+	cache := memcachedcache.New[int, int](memcache.NewFromSelector(servers), time.Minute,
+		ugulru.IntKeyCodec[int]{}, ugulru.JSONCodec[int]{})
+
+	const keys = 300
+	for i := range keys {
+		cache.Put(i, i)
+	}
+	for _, srv := range srvs {
+		assert.InDelta(t, keys/3, srv.len(), keys/6, "keys should be spread across servers")
+	}
+
+	// Removing a server only misses the keys it held
+	servers.Remove(addrs[0])
+	misses := 0
+	for i := range keys {
+		if _, ok := cache.Get(i); !ok {
+			misses++
+		}
+	}
+	assert.Equal(t, srvs[0].len(), misses)
+
+	_, err = memcachedcache.NewServers("invalid address")
+	assert.Error(t, err)
+}
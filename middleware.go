@@ -0,0 +1,246 @@
+package ugulru
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CachedResponse is an HTTP response stored by Middleware.
+type CachedResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+	// Stored is the time the response was stored, from which the Age header of the cached response is updated when
+	// it is served.
+	Stored time.Time
+}
+
+// age returns the age of the response at the given time, in seconds: the Age header it was stored with plus the time
+// it spent in the cache.
+func (r CachedResponse) age(now time.Time) int {
+	age, err := strconv.Atoi(r.Header.Get("Age"))
+	if err != nil || age < 0 {
+		age = 0
+	}
+	return age + int(now.Sub(r.Stored)/time.Second)
+}
+
+// MiddlewareConfig configures Middleware. Zero fields take their default value.
+type MiddlewareConfig struct {
+	// Key returns the cache key of a request. By default, it is the method and the URL of the request.
+	Key func(r *http.Request) string
+	// Vary lists the request headers whose values are added to the key, such as Accept-Encoding or Accept-Language,
+	// for responses that depend on them. Responses whose Vary header lists other request headers are not cached.
+	Vary []string
+	// TTL returns the time-to-live of the response to a request, e.g. depending on its route. Zero uses the TTL of the
+	// cache and a negative TTL disables caching the response. By default, the TTL of the cache is used.
+	TTL func(r *http.Request) time.Duration
+	// Cacheable reports whether a response with the given status can be cached. By default, only 200 OK responses are.
+	Cacheable func(status int) bool
+}
+
+// Middleware returns HTTP server middleware caching the responses of the wrapped handler in the given cache. Only GET
+// and HEAD requests are cached, and requests carrying an Authorization or a Cookie header bypass the cache, since their
+// responses are likely specific to a user. Responses are not cached if they set a cookie, if their Cache-Control header
+// contains no-store, no-cache or private, or if they vary on request headers not listed in MiddlewareConfig.Vary. The
+// freshness lifetime given by the s-maxage or max-age directives, less the Age header, or by the Expires header caps
+// the TTL of the cached response, so it is never served after the handler says it's stale. Only the responses that can
+// be stored are buffered while they are written. Cached responses are served with their status, headers and body, an
+// Age header updated with the time they spent in the cache, and an X-Cache header set to HIT, while other responses
+// get an X-Cache header set to MISS.
+func Middleware(cache *InMemoryCache[string, CachedResponse], config MiddlewareConfig) func(http.Handler) http.Handler {
+	if config.Key == nil {
+		config.Key = func(r *http.Request) string {
+			return r.Method + " " + r.URL.String()
+		}
+	}
+	if config.Cacheable == nil {
+		config.Cacheable = func(status int) bool {
+			return status == http.StatusOK
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead || private(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			var ttl time.Duration
+			if config.TTL != nil {
+				if ttl = config.TTL(r); ttl < 0 {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			key := config.Key(r)
+			for _, name := range config.Vary {
+				key += "\n" + name + ": " + strings.Join(r.Header.Values(name), ",")
+			}
+			if resp, ok := cache.Get(key); ok {
+				header := w.Header()
+				for name, values := range resp.Header {
+					header[name] = values
+				}
+				if !resp.Stored.IsZero() {
+					header.Set("Age", strconv.Itoa(resp.age(time.Now())))
+				}
+				header.Set("X-Cache", "HIT")
+				w.WriteHeader(resp.Status)
+				w.Write(resp.Body)
+				return
+			}
+
+			w.Header().Set("X-Cache", "MISS")
+			var header http.Header
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			rec.record = func(status int) bool {
+				if !config.Cacheable(status) || !storable(w.Header(), config.Vary) {
+					return false
+				}
+				if lifetime, ok := freshness(w.Header(), time.Now()); ok {
+					if lifetime <= 0 {
+						return false
+					}
+					if ttl == 0 {
+						ttl = cache.TTL()
+					}
+					ttl = min(ttl, lifetime)
+				}
+				header = w.Header().Clone()
+				header.Del("X-Cache")
+				return true
+			}
+			next.ServeHTTP(rec, r)
+			if !rec.wroteHeader {
+				// The handler wrote nothing, so the server sends an empty 200 OK response
+				rec.recording = rec.record(rec.status)
+			}
+			if !rec.recording {
+				return
+			}
+			resp := CachedResponse{Status: rec.status, Header: header, Body: rec.body.Bytes(), Stored: time.Now()}
+			cache.PutWithTTL(key, resp, 0, ttl)
+		})
+	}
+}
+
+// private reports whether the given request carries credentials, so its response is likely specific to a user.
+func private(r *http.Request) bool {
+	return r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != ""
+}
+
+// storable reports whether a response with the given headers can be stored in a shared cache whose keys include the
+// given request headers.
+func storable(header http.Header, vary []string) bool {
+	if header.Get("Set-Cookie") != "" {
+		return false
+	}
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, _, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			switch strings.ToLower(name) {
+			case "no-store", "no-cache", "private":
+				return false
+			}
+		}
+	}
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "*" || name != "" && !slices.ContainsFunc(vary, func(v string) bool {
+				return http.CanonicalHeaderKey(v) == name
+			}) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// freshness returns the freshness lifetime remaining for a response with the given headers, received at the given time,
+// and false if the headers don't set one. The s-maxage directive takes precedence over max-age, which takes precedence
+// over the Expires header. An invalid Expires header means the response is already stale.
+func freshness(header http.Header, now time.Time) (time.Duration, bool) {
+	maxAge := -1
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			seconds, err := strconv.Atoi(strings.Trim(arg, `"`))
+			if err != nil || seconds < 0 {
+				continue
+			}
+			switch strings.ToLower(name) {
+			case "s-maxage":
+				return age(header, seconds), true
+			case "max-age":
+				maxAge = seconds
+			}
+		}
+	}
+	if maxAge >= 0 {
+		return age(header, maxAge), true
+	}
+
+	value := header.Get("Expires")
+	if value == "" {
+		return 0, false
+	}
+	expires, err := http.ParseTime(value)
+	if err != nil {
+		return 0, true
+	}
+	if date, err := http.ParseTime(header.Get("Date")); err == nil {
+		now = date
+	}
+	return expires.Sub(now), true
+}
+
+// age returns the given max age, in seconds, less the Age header of a response with the given headers.
+func age(header http.Header, maxAge int) time.Duration {
+	age, err := strconv.Atoi(header.Get("Age"))
+	if err != nil || age < 0 {
+		age = 0
+	}
+	return time.Duration(maxAge-age) * time.Second
+}
+
+// responseRecorder passes a response through while recording its status and, if record returns true for the status
+// once the headers are written, its body.
+type responseRecorder struct {
+	http.ResponseWriter
+	record      func(status int) bool
+	status      int
+	wroteHeader bool
+	recording   bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+		r.recording = r.record(status)
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	if r.recording {
+		r.body.Write(b)
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying response writer, for http.ResponseController.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,278 @@
+package ugulru_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingHandler responds with the number of requests it served so far.
+func countingHandler(calls *int, status int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(status)
+		w.Write([]byte(strings.Repeat("x", *calls)))
+	})
+}
+
+func serve(handler http.Handler, method, target string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddleware(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, ugulru.CachedResponse](10, time.Minute)
+	var calls int
+	handler := ugulru.Middleware(cache, ugulru.MiddlewareConfig{})(countingHandler(&calls, http.StatusOK))
+
+	rec := serve(handler, http.MethodGet, "/a?x=1", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "MISS", rec.Header().Get("X-Cache"))
+	assert.Equal(t, "x", rec.Body.String())
+
+	rec = serve(handler, http.MethodGet, "/a?x=1", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "HIT", rec.Header().Get("X-Cache"))
+	assert.Equal(t, "text/plain", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "x", rec.Body.String())
+	assert.Equal(t, 1, calls)
+
+	// Other URLs and methods are cached separately
+	assert.Equal(t, "xx", serve(handler, http.MethodGet, "/a?x=2", nil).Body.String())
+	assert.Equal(t, "MISS", serve(handler, http.MethodHead, "/a?x=1", nil).Header().Get("X-Cache"))
+	assert.Equal(t, 3, calls)
+
+	// Unsafe methods are never cached
+	for range 2 {
+		rec = serve(handler, http.MethodPost, "/a?x=1", nil)
+		assert.Empty(t, rec.Header().Get("X-Cache"))
+	}
+	assert.Equal(t, 5, calls)
+}
+
+func TestMiddlewareStatus(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, ugulru.CachedResponse](10, time.Minute)
+	var calls int
+	handler := ugulru.Middleware(cache, ugulru.MiddlewareConfig{})(countingHandler(&calls, http.StatusNotFound))
+	serve(handler, http.MethodGet, "/missing", nil)
+	serve(handler, http.MethodGet, "/missing", nil)
+	assert.Equal(t, 2, calls, "only 200 OK responses should be cached by default")
+
+	calls = 0
+	handler = ugulru.Middleware(cache, ugulru.MiddlewareConfig{
+		Cacheable: func(status int) bool { return status == http.StatusNotFound },
+	})(countingHandler(&calls, http.StatusNotFound))
+	serve(handler, http.MethodGet, "/missing", nil)
+	rec := serve(handler, http.MethodGet, "/missing", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "HIT", rec.Header().Get("X-Cache"))
+	assert.Equal(t, 1, calls)
+}
+
+func TestMiddlewareNoStore(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, ugulru.CachedResponse](10, time.Minute)
+	var calls int
+	handler := ugulru.Middleware(cache, ugulru.MiddlewareConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "max-age=0, Private")
+		w.Write([]byte("secret"))
+	}))
+	serve(handler, http.MethodGet, "/me", nil)
+	serve(handler, http.MethodGet, "/me", nil)
+	assert.Equal(t, 2, calls)
+}
+
+func TestMiddlewareKeyAndVary(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, ugulru.CachedResponse](10, time.Minute)
+	var calls int
+	handler := ugulru.Middleware(cache, ugulru.MiddlewareConfig{
+		// Ignore the query string
+		Key:  func(r *http.Request) string { return r.Method + " " + r.URL.Path },
+		Vary: []string{"Accept-Language"},
+	})(countingHandler(&calls, http.StatusOK))
+
+	en := http.Header{"Accept-Language": {"en"}}
+	fr := http.Header{"Accept-Language": {"fr"}}
+	assert.Equal(t, "x", serve(handler, http.MethodGet, "/page?a=1", en).Body.String())
+	assert.Equal(t, "x", serve(handler, http.MethodGet, "/page?a=2", en).Body.String())
+	assert.Equal(t, "xx", serve(handler, http.MethodGet, "/page", fr).Body.String())
+	assert.Equal(t, "xx", serve(handler, http.MethodGet, "/page", fr).Body.String())
+	assert.Equal(t, 2, calls)
+}
+
+func TestMiddlewareTTL(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, ugulru.CachedResponse](10, time.Minute)
+	var calls int
+	handler := ugulru.Middleware(cache, ugulru.MiddlewareConfig{
+		TTL: func(r *http.Request) time.Duration {
+			switch {
+			case strings.HasPrefix(r.URL.Path, "/live"):
+				return 100 * time.Millisecond
+			case strings.HasPrefix(r.URL.Path, "/admin"):
+				return -1
+			}
+			return 0
+		},
+	})(countingHandler(&calls, http.StatusOK))
+
+	serve(handler, http.MethodGet, "/live", nil)
+	_, expiry, ok := cache.GetWithExpiry("GET /live")
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(100*time.Millisecond), expiry, 50*time.Millisecond)
+	serve(handler, http.MethodGet, "/static", nil)
+	_, expiry, ok = cache.GetWithExpiry("GET /static")
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expiry, time.Second)
+
+	time.Sleep(150 * time.Millisecond)
+	assert.Equal(t, "MISS", serve(handler, http.MethodGet, "/live", nil).Header().Get("X-Cache"))
+	assert.Equal(t, "HIT", serve(handler, http.MethodGet, "/static", nil).Header().Get("X-Cache"))
+
+	serve(handler, http.MethodGet, "/admin", nil)
+	rec := serve(handler, http.MethodGet, "/admin", nil)
+	assert.Empty(t, rec.Header().Get("X-Cache"))
+	assert.Equal(t, 5, calls)
+}
+
+func TestMiddlewareFreshness(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		ttl    time.Duration // zero if the response must not be cached
+	}{
+		{"none", http.Header{}, time.Minute},
+		{"max-age", http.Header{"Cache-Control": {"public, max-age=30"}}, 30 * time.Second},
+		{"max-age and age", http.Header{"Cache-Control": {"max-age=30"}, "Age": {"20"}}, 10 * time.Second},
+		{"s-maxage", http.Header{"Cache-Control": {"max-age=5, s-maxage=20"}}, 20 * time.Second},
+		{"longer than the cache", http.Header{"Cache-Control": {"max-age=3600"}}, time.Minute},
+		{"stale", http.Header{"Cache-Control": {"max-age=10"}, "Age": {"15"}}, 0},
+		{"expires", http.Header{"Expires": {time.Now().Add(40 * time.Second).UTC().Format(http.TimeFormat)}}, 40 * time.Second},
+		{"expires and date", http.Header{
+			"Date":    {time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)},
+			"Expires": {time.Now().Add(-time.Hour + 15*time.Second).UTC().Format(http.TimeFormat)},
+		}, 15 * time.Second},
+		{"invalid expires", http.Header{"Expires": {"0"}}, 0},
+		{"max-age over expires", http.Header{"Cache-Control": {"max-age=25"}, "Expires": {"0"}}, 25 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := ugulru.NewInMemoryCache[string, ugulru.CachedResponse](10, time.Minute)
+			handler := ugulru.Middleware(cache, ugulru.MiddlewareConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for name, values := range tt.header {
+					w.Header()[name] = values
+				}
+				w.Write([]byte("body"))
+			}))
+			serve(handler, http.MethodGet, "/", nil)
+
+			_, expiry, ok := cache.GetWithExpiry("GET /")
+			if tt.ttl == 0 {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.WithinDuration(t, time.Now().Add(tt.ttl), expiry, 2*time.Second)
+		})
+	}
+}
+
+func TestMiddlewarePrivate(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  http.Header
+		response http.Header
+		vary     []string
+		cached   bool
+	}{
+		{"authorization", http.Header{"Authorization": {"Bearer token"}}, nil, nil, false},
+		{"cookie", http.Header{"Cookie": {"session=1"}}, nil, nil, false},
+		{"set-cookie", nil, http.Header{"Set-Cookie": {"session=1"}}, nil, false},
+		{"no-cache", nil, http.Header{"Cache-Control": {"no-cache"}}, nil, false},
+		{"qualified no-cache", nil, http.Header{"Cache-Control": {`max-age=60, no-cache="Set-Cookie"`}}, nil, false},
+		{"vary star", nil, http.Header{"Vary": {"*"}}, nil, false},
+		{"vary unlisted", nil, http.Header{"Vary": {"Accept-Encoding, Accept-Language"}}, []string{"Accept-Encoding"}, false},
+		{"vary listed", nil, http.Header{"Vary": {"accept-encoding"}}, []string{"Accept-Encoding"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := ugulru.NewInMemoryCache[string, ugulru.CachedResponse](10, time.Minute)
+			var calls int
+			handler := ugulru.Middleware(cache, ugulru.MiddlewareConfig{Vary: tt.vary})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				for name, values := range tt.response {
+					w.Header()[name] = values
+				}
+				w.Write([]byte("body"))
+			}))
+			serve(handler, http.MethodGet, "/", tt.request)
+			serve(handler, http.MethodGet, "/", tt.request)
+			if tt.cached {
+				assert.Equal(t, 1, calls)
+			} else {
+				assert.Equal(t, 2, calls)
+			}
+		})
+	}
+
+	// A response cached for an anonymous request is not served to a request with credentials
+	cache := ugulru.NewInMemoryCache[string, ugulru.CachedResponse](10, time.Minute)
+	var calls int
+	handler := ugulru.Middleware(cache, ugulru.MiddlewareConfig{})(countingHandler(&calls, http.StatusOK))
+	serve(handler, http.MethodGet, "/", nil)
+	rec := serve(handler, http.MethodGet, "/", http.Header{"Authorization": {"Bearer token"}})
+	assert.Empty(t, rec.Header().Get("X-Cache"))
+	assert.Equal(t, 2, calls)
+}
+
+func TestMiddlewareAge(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, ugulru.CachedResponse](10, time.Minute)
+	var calls int
+	handler := ugulru.Middleware(cache, ugulru.MiddlewareConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Age", "10")
+		w.Write([]byte("body"))
+	}))
+
+	rec := serve(handler, http.MethodGet, "/", nil)
+	assert.Equal(t, "10", rec.Header().Get("Age"))
+
+	// Hits add the time spent in the cache to the Age the response was stored with
+	resp, ok := cache.Get("GET /")
+	require.True(t, ok)
+	resp.Stored = resp.Stored.Add(-5 * time.Second)
+	cache.PutWithTTL("GET /", resp, 0, time.Minute)
+	rec = serve(handler, http.MethodGet, "/", nil)
+	assert.Equal(t, "HIT", rec.Header().Get("X-Cache"))
+	assert.Equal(t, "15", rec.Header().Get("Age"))
+	assert.Equal(t, "10", resp.Header.Get("Age"))
+	assert.Equal(t, 1, calls)
+}
+
+func TestMiddlewareSentHeaders(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, ugulru.CachedResponse](10, time.Minute)
+	handler := ugulru.Middleware(cache, ugulru.MiddlewareConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("body"))
+		// Headers changed after the response is written are not sent, so they don't make it storable or not
+		w.Header().Set("Cache-Control", "no-store")
+	}))
+
+	rec := serve(handler, http.MethodGet, "/", nil)
+	assert.Equal(t, "body", rec.Body.String())
+	resp, ok := cache.Get("GET /")
+	require.True(t, ok)
+	assert.Equal(t, "body", string(resp.Body))
+	assert.Empty(t, resp.Header.Get("Cache-Control"))
+}
@@ -0,0 +1,130 @@
+package ugulru
+
+import (
+	"crypto/cipher"
+	"strings"
+)
+
+// NamespacedCache is a view of a shared cache with string keys that scopes all the keys to a namespace, so several
+// components can share the capacity of one cache without key collisions.
+type NamespacedCache[V any] struct {
+	cache  *InMemoryCache[string, V]
+	prefix string
+}
+
+var _ Cache[string, any] = (*NamespacedCache[any])(nil)
+
+// Namespace returns a view of the cache that prefixes all the keys with the name and a colon. The name must not
+// contain a colon, otherwise the keys of different namespaces might collide.
+func Namespace[V any](cache *InMemoryCache[string, V], name string) *NamespacedCache[V] {
+	return &NamespacedCache[V]{cache: cache, prefix: name + ":"}
+}
+
+// Get retrieves a value from the namespace.
+func (n *NamespacedCache[V]) Get(key string) (V, bool) {
+	return n.cache.Get(n.prefix + key)
+}
+
+// Put stores a value in the namespace.
+func (n *NamespacedCache[V]) Put(key string, value V) {
+	n.cache.Put(n.prefix+key, value)
+}
+
+// Remove deletes a key from the namespace.
+func (n *NamespacedCache[V]) Remove(key string) {
+	n.cache.Remove(n.prefix + key)
+}
+
+// RemoveExpired removes all expired entries from the shared cache, including those of other namespaces.
+func (n *NamespacedCache[V]) RemoveExpired() {
+	n.cache.RemoveExpired()
+}
+
+// Load retrieves a value from the namespace, calling the loader on a miss.
+func (n *NamespacedCache[V]) Load(key string, loader func() (V, error)) (V, error) {
+	return n.cache.Load(n.prefix+key, loader)
+}
+
+// Clear removes all the entries of the namespace, leaving the other namespaces intact, and returns the number of
+// removed entries.
+func (n *NamespacedCache[V]) Clear() int {
+	return n.cache.RemoveIf(func(key string, _ V) bool {
+		return strings.HasPrefix(key, n.prefix)
+	})
+}
+
+// EncryptedNamespacedCache is a view of a shared cache of encrypted values that scopes all the keys to a namespace and
+// encrypts the values with the key of the namespace, so the tenants of a shared cache can't read each other's values.
+type EncryptedNamespacedCache[V any] struct {
+	ns    *NamespacedCache[[]byte]
+	codec *AEADCodec[V]
+}
+
+var _ Cache[string, any] = (*EncryptedNamespacedCache[any])(nil)
+
+// EncryptedNamespace returns a view of the cache that prefixes all the keys with the name and a colon, like Namespace,
+// and stores the values encoded with the given codec and encrypted with the given AEAD, the key of the namespace. The
+// name is authenticated with every value, so a value copied from another namespace fails to decrypt even if both use
+// the same key. Values that fail to decrypt are treated as missing. Snapshots of the shared cache only hold encrypted
+// values, and WithEncryption still encrypts them as a whole.
+func EncryptedNamespace[V any](cache *InMemoryCache[string, []byte], name string, codec Codec[V], aead cipher.AEAD) *EncryptedNamespacedCache[V] {
+	return &EncryptedNamespacedCache[V]{
+		ns:    Namespace(cache, name),
+		codec: NewAEADCodec(codec, aead, []byte("ugulru namespace "+name)),
+	}
+}
+
+// Get retrieves and decrypts a value from the namespace.
+func (n *EncryptedNamespacedCache[V]) Get(key string) (V, bool) {
+	data, ok := n.ns.Get(key)
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	value, err := n.codec.Decode(data)
+	return value, err == nil
+}
+
+// Put encrypts and stores a value in the namespace. If the value can't be encoded, the key is removed instead, so
+// its previous value isn't served anymore.
+func (n *EncryptedNamespacedCache[V]) Put(key string, value V) {
+	data, err := n.codec.Encode(value)
+	if err != nil {
+		n.ns.Remove(key)
+		return
+	}
+	n.ns.Put(key, data)
+}
+
+// Remove deletes a key from the namespace.
+func (n *EncryptedNamespacedCache[V]) Remove(key string) {
+	n.ns.Remove(key)
+}
+
+// RemoveExpired removes all expired entries from the shared cache, including those of other namespaces.
+func (n *EncryptedNamespacedCache[V]) RemoveExpired() {
+	n.ns.RemoveExpired()
+}
+
+// Load retrieves and decrypts a value from the namespace, calling the loader on a miss and storing the encrypted
+// value it returns.
+func (n *EncryptedNamespacedCache[V]) Load(key string, loader func() (V, error)) (V, error) {
+	data, err := n.ns.Load(key, func() ([]byte, error) {
+		value, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		return n.codec.Encode(value)
+	})
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	return n.codec.Decode(data)
+}
+
+// Clear removes all the entries of the namespace, leaving the other namespaces intact, and returns the number of
+// removed entries.
+func (n *EncryptedNamespacedCache[V]) Clear() int {
+	return n.ns.Clear()
+}
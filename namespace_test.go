@@ -0,0 +1,89 @@
+package ugulru_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamespace(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](10, 5*time.Minute)
+	users := ugulru.Namespace(cache, "users")
+	orders := ugulru.Namespace(cache, "orders")
+
+	users.Put("1", 10)
+	orders.Put("1", 20)
+	orders.Put("2", 30)
+
+	value, ok := users.Get("1")
+	assert.True(t, ok)
+	assert.Equal(t, 10, value)
+	value, ok = orders.Get("1")
+	assert.True(t, ok)
+	assert.Equal(t, 20, value)
+	value, ok = cache.Get("orders:2")
+	assert.True(t, ok)
+	assert.Equal(t, 30, value)
+
+	value, err := users.Load("2", func() (int, error) { return 40, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 40, value)
+
+	assert.Equal(t, 2, orders.Clear())
+	_, ok = orders.Get("1")
+	assert.False(t, ok)
+	_, ok = users.Get("1")
+	assert.True(t, ok)
+
+	users.Remove("1")
+	_, ok = users.Get("1")
+	assert.False(t, ok)
+}
+
+func TestEncryptedNamespace(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, []byte](10, 5*time.Minute)
+	acme := ugulru.EncryptedNamespace(cache, "acme", ugulru.JSONCodec[string]{}, newAEAD(t, "0123456789abcdef0123456789abcdef"))
+	globex := ugulru.EncryptedNamespace(cache, "globex", ugulru.JSONCodec[string]{}, newAEAD(t, "fedcba9876543210fedcba9876543210"))
+
+	acme.Put("email", "alice@example.com")
+	value, ok := acme.Get("email")
+	assert.True(t, ok)
+	assert.Equal(t, "alice@example.com", value)
+	_, ok = globex.Get("email")
+	assert.False(t, ok)
+
+	// Values are stored encrypted
+	data, ok := cache.Get("acme:email")
+	require.True(t, ok)
+	assert.NotContains(t, string(data), "alice")
+
+	// A value copied to another namespace doesn't decrypt, even with the same key
+	cache.Put("globex:email", data)
+	_, ok = globex.Get("email")
+	assert.False(t, ok)
+	shared := ugulru.EncryptedNamespace(cache, "shared", ugulru.JSONCodec[string]{}, newAEAD(t, "0123456789abcdef0123456789abcdef"))
+	cache.Put("shared:email", data)
+	_, ok = shared.Get("email")
+	assert.False(t, ok)
+
+	value, err := globex.Load("phone", func() (string, error) { return "+1 555 0100", nil })
+	assert.NoError(t, err)
+	assert.Equal(t, "+1 555 0100", value)
+	value, ok = globex.Get("phone")
+	assert.True(t, ok)
+	assert.Equal(t, "+1 555 0100", value)
+	errLoad := errors.New("load failed")
+	_, err = globex.Load("fax", func() (string, error) { return "", errLoad })
+	assert.ErrorIs(t, err, errLoad)
+
+	assert.Equal(t, 2, globex.Clear())
+	_, ok = acme.Get("email")
+	assert.True(t, ok)
+	acme.Remove("email")
+	_, ok = acme.Get("email")
+	assert.False(t, ok)
+}
@@ -0,0 +1,22 @@
+module github.com/machine23/ugulru/natspubsub
+
+go 1.24.0
+
+require (
+	github.com/machine23/ugulru v0.0.0-00010101000000-000000000000
+	github.com/nats-io/nats.go v1.48.0
+	github.com/stretchr/testify v1.9.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/klauspost/compress v1.18.0 // indirect
+	github.com/nats-io/nkeys v0.4.11 // indirect
+	github.com/nats-io/nuid v1.0.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/crypto v0.37.0 // indirect
+	golang.org/x/sys v0.32.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/machine23/ugulru => ../
@@ -0,0 +1,53 @@
+// Package natspubsub provides a ugulru.PubSub over NATS, so deployments running NATS rather than Redis can keep the
+// in-memory caches of their replicas coherent with ugulru.Invalidator.
+package natspubsub
+
+import (
+	"context"
+
+	"github.com/machine23/ugulru"
+	"github.com/nats-io/nats.go"
+)
+
+// PubSub is a ugulru.PubSub over a NATS subject.
+type PubSub struct {
+	conn    *nats.Conn
+	subject string
+}
+
+var _ ugulru.PubSub = (*PubSub)(nil)
+
+// New creates a new pub/sub over the given NATS subject. The connection is owned by the caller, who must close it
+// once the pub/sub is no longer used.
+func New(conn *nats.Conn, subject string) *PubSub {
+	return &PubSub{conn: conn, subject: subject}
+}
+
+// Publish publishes the given payload to the subject. NATS buffers published messages, so Publish doesn't wait for
+// the server unless the context has a deadline, in which case it flushes the connection before returning.
+func (p *PubSub) Publish(ctx context.Context, payload []byte) error {
+	if err := p.conn.Publish(p.subject, payload); err != nil {
+		return err
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return p.conn.FlushWithContext(ctx)
+	}
+	return nil
+}
+
+// Subscribe calls handler with every payload published to the subject until unsubscribe is called. Payloads are
+// handled one at a time, in the order they were published.
+func (p *PubSub) Subscribe(handler func(payload []byte)) (func() error, error) {
+	sub, err := p.conn.Subscribe(p.subject, func(msg *nats.Msg) {
+		handler(msg.Data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Make sure the server has registered the subscription
+	if err := p.conn.Flush(); err != nil {
+		sub.Unsubscribe()
+		return nil, err
+	}
+	return sub.Unsubscribe, nil
+}
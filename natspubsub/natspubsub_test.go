@@ -0,0 +1,144 @@
+package natspubsub_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/machine23/ugulru/natspubsub"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// server is a fake NATS server supporting the subset of the protocol used by the pub/sub.
+type server struct {
+	mu   sync.Mutex
+	subs map[*bufio.Writer]map[string]string // subscription ids by subject, by client
+}
+
+func startServer(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		ln.Close()
+	})
+
+	s := &server{subs: make(map[*bufio.Writer]map[string]string)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return "nats://" + ln.Addr().String()
+}
+
+func (s *server) serve(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	s.mu.Lock()
+	s.subs[w] = make(map[string]string)
+	fmt.Fprint(w, `INFO {"server_id":"test","version":"2.10.0","max_payload":1048576}`+"\r\n")
+	w.Flush()
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subs, w)
+		s.mu.Unlock()
+	}()
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		s.mu.Lock()
+		switch strings.ToUpper(fields[0]) {
+		case "PING":
+			fmt.Fprint(w, "PONG\r\n")
+			w.Flush()
+		case "SUB":
+			s.subs[w][fields[1]] = fields[len(fields)-1]
+		case "UNSUB":
+			for subject, sid := range s.subs[w] {
+				if sid == fields[1] {
+					delete(s.subs[w], subject)
+				}
+			}
+		case "PUB":
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			payload := make([]byte, size+2)
+			s.mu.Unlock()
+			io.ReadFull(r, payload)
+			s.mu.Lock()
+			for client, subs := range s.subs {
+				if sid, ok := subs[fields[1]]; ok {
+					fmt.Fprintf(client, "MSG %s %s %d\r\n%s", fields[1], sid, size, payload)
+					client.Flush()
+				}
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func connect(t *testing.T, url string) *nats.Conn {
+	t.Helper()
+
+	conn, err := nats.Connect(url)
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+	return conn
+}
+
+func TestPubSub(t *testing.T) {
+	url := startServer(t)
+	local := ugulru.NewInMemoryCache[string, int](10, time.Minute)
+	remote := ugulru.NewInMemoryCache[string, int](10, time.Minute)
+	local.Put("key1", 1)
+	remote.Put("key1", 1)
+
+	localInvalidator, err := ugulru.NewInvalidator(local, natspubsub.New(connect(t, url), "invalidations"),
+		ugulru.StringKeyCodec[string]{}, ugulru.WithReplication[string, int](ugulru.JSONCodec[int]{}))
+	require.NoError(t, err)
+	defer localInvalidator.Close()
+	remoteInvalidator, err := ugulru.NewInvalidator(remote, natspubsub.New(connect(t, url), "invalidations"),
+		ugulru.StringKeyCodec[string]{}, ugulru.WithReplication[string, int](ugulru.JSONCodec[int]{}))
+	require.NoError(t, err)
+	defer remoteInvalidator.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, localInvalidator.Remove(ctx, "key1"))
+	assert.Eventually(t, func() bool {
+		_, ok := remote.Get("key1")
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+
+	// Hot values are replicated
+	require.NoError(t, localInvalidator.Put(ctx, "key2", 2))
+	assert.Eventually(t, func() bool {
+		value, ok := remote.Get("key2")
+		return ok && value == 2
+	}, time.Second, 10*time.Millisecond)
+}
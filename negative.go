@@ -0,0 +1,39 @@
+package ugulru
+
+import "time"
+
+// WithNegativeCaching makes Load cache the loader errors recognized by isNegative, such as a well-known "not found"
+// error, for the given TTL, which is usually shorter than the TTL of regular entries. While a negative entry is live,
+// Load returns the cached error without calling the loader and Get reports the key as missing.
+func WithNegativeCaching[K comparable, V any](isNegative func(err error) bool, ttl time.Duration) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.isNegative = isNegative
+		c.negativeTTL = ttl
+	}
+}
+
+// negative returns the cached error of the live negative entry for the given key, if any. It must be called with the
+// lock held.
+func (c *InMemoryCache[K, V]) negative(key K) error {
+	entry, ok := c.cache[key]
+	if !ok {
+		return nil
+	}
+	if entry.err == nil || c.expired(entry) {
+		return nil
+	}
+	return entry.err
+}
+
+// storeNegative caches the given loader error if it is recognized as a negative result and reports whether it did so.
+// Negative results are not buffered while the cache is quiesced. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) storeNegative(key K, err error) bool {
+	if c.isNegative == nil || c.quiesced || !c.isNegative(err) {
+		return false
+	}
+	var zero V
+	entry := c.put(key, zero)
+	entry.err = err
+	entry.ttl = c.negativeTTL
+	return true
+}
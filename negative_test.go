@@ -0,0 +1,58 @@
+package ugulru_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithNegativeCaching(t *testing.T) {
+	errNotFound := errors.New("not found")
+	errUnavailable := errors.New("unavailable")
+	isNotFound := func(err error) bool { return errors.Is(err, errNotFound) }
+	cache := ugulru.NewInMemoryCache(3, 5*time.Minute, ugulru.WithNegativeCaching[string, int](isNotFound, 100*time.Millisecond))
+
+	calls := 0
+	loader := func(err error) func() (int, error) {
+		return func() (int, error) {
+			calls++
+			return 0, err
+		}
+	}
+
+	// Negative results are cached
+	_, err := cache.Load("key1", loader(errNotFound))
+	assert.ErrorIs(t, err, errNotFound)
+	_, err = cache.Load("key1", loader(errNotFound))
+	assert.ErrorIs(t, err, errNotFound)
+	assert.Equal(t, 1, calls)
+
+	// Negative entries are not visible to Get or iteration
+	_, ok := cache.Get("key1")
+	assert.False(t, ok)
+	assert.Empty(t, slices.Collect(cache.Keys()))
+
+	// Other errors are not cached
+	_, err = cache.Load("key2", loader(errUnavailable))
+	assert.ErrorIs(t, err, errUnavailable)
+	_, err = cache.Load("key2", loader(errUnavailable))
+	assert.ErrorIs(t, err, errUnavailable)
+	assert.Equal(t, 3, calls)
+
+	// Negative entries expire after their own TTL
+	time.Sleep(150 * time.Millisecond)
+	value, err := cache.Load("key1", func() (int, error) { return 1, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+
+	// Put replaces a negative entry
+	_, _ = cache.Load("key3", loader(errNotFound))
+	cache.Put("key3", 3)
+	value, ok = cache.Get("key3")
+	assert.True(t, ok)
+	assert.Equal(t, 3, value)
+}
@@ -0,0 +1,60 @@
+package ugulru
+
+import "time"
+
+// Option configures an InMemoryCache.
+type Option[K comparable, V any] func(*InMemoryCache[K, V])
+
+// WithSlidingExpiration makes every hit renew the lifetime of the entry, so entries only expire when they have not
+// been read or written for the TTL duration.
+func WithSlidingExpiration[K comparable, V any]() Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.sliding = true
+	}
+}
+
+// WithExpireAfterAccess makes entries expire when they have not been read or written for the given duration, in
+// addition to expiring the TTL duration after they were last written, whichever comes first.
+func WithExpireAfterAccess[K comparable, V any](d time.Duration) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.tti = d
+	}
+}
+
+// WithTTLJitter randomly shortens or extends the TTL of every written entry by up to the given fraction of the TTL
+// (e.g. 0.1 for ±10%), so entries loaded at the same time, such as after a restart, don't all expire at once.
+func WithTTLJitter[K comparable, V any](fraction float64) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.jitter = fraction
+	}
+}
+
+// WithTTLFromValue derives the TTL of every written entry from its value, so values that carry their own expiry, such
+// as tokens or DNS records, are cached exactly until they expire. A non-positive TTL means the value has already
+// expired and is never served. The TTL jitter doesn't apply to such entries.
+func WithTTLFromValue[K comparable, V any](ttl func(value V) time.Duration) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.ttlFromValue = ttl
+	}
+}
+
+// WithWarmupRamp shortens the TTL of entries written during the given duration after the cache was created. The TTL
+// starts at the given fraction of the configured TTL and grows linearly to the full TTL at the end of the warmup, so a
+// freshly started instance, e.g. one warmed with restored data, doesn't serve possibly stale values for a full TTL.
+// Entries with their own TTL are not affected.
+func WithWarmupRamp[K comparable, V any](d time.Duration, from float64) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.warmup = d
+		c.warmupFrom = from
+	}
+}
+
+// ramp returns the given TTL shortened according to the warmup ramp for an entry written at the given time.
+func (c *InMemoryCache[K, V]) ramp(written int64, ttl time.Duration) time.Duration {
+	warmup := c.warmup.Milliseconds()
+	if warmup <= 0 || written >= warmup {
+		return ttl
+	}
+	fraction := c.warmupFrom + (1-c.warmupFrom)*float64(written)/float64(warmup)
+	return time.Duration(float64(ttl) * fraction)
+}
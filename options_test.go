@@ -0,0 +1,110 @@
+package ugulru_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSlidingExpiration(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(2, 200*time.Millisecond, ugulru.WithSlidingExpiration[string, int]())
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+
+	for range 3 {
+		time.Sleep(100 * time.Millisecond)
+		_, ok := cache.Get("key1")
+		assert.True(t, ok, "key1 should be kept alive by reads")
+	}
+
+	_, ok := cache.Get("key2")
+	assert.False(t, ok, "key2 should expire without reads")
+
+	time.Sleep(250 * time.Millisecond)
+	_, ok = cache.Get("key1")
+	assert.False(t, ok, "key1 should expire once reads stop")
+}
+
+func TestWithExpireAfterAccess(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(3, 400*time.Millisecond, ugulru.WithExpireAfterAccess[string, int](150*time.Millisecond))
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+
+	// key1 is accessed often enough but still expires after the write TTL
+	for range 3 {
+		time.Sleep(100 * time.Millisecond)
+		_, ok := cache.Get("key1")
+		assert.True(t, ok, "key1 should be kept alive by reads")
+	}
+	_, ok := cache.Get("key2")
+	assert.False(t, ok, "key2 should expire after not being accessed")
+
+	time.Sleep(150 * time.Millisecond)
+	_, ok = cache.Get("key1")
+	assert.False(t, ok, "key1 should expire after the write TTL")
+
+	// The expiry reflects the earlier of the two deadlines
+	cache.Put("key3", 3)
+	_, expiry, ok := cache.GetWithExpiry("key3")
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(150*time.Millisecond), expiry, 50*time.Millisecond)
+}
+
+func TestWithTTLJitter(t *testing.T) {
+	const ttl = time.Minute
+	cache := ugulru.NewInMemoryCache(100, ttl, ugulru.WithTTLJitter[int, int](0.1))
+
+	expiries := make(map[time.Time]bool)
+	for i := range 100 {
+		cache.Put(i, i)
+		_, expiry, ok := cache.GetWithExpiry(i)
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(ttl), expiry, ttl/10+time.Second)
+		expiries[expiry] = true
+	}
+	assert.Greater(t, len(expiries), 50, "expiries should be spread out")
+}
+
+func TestWithTTLFromValue(t *testing.T) {
+	type token struct {
+		value   string
+		expires time.Time
+	}
+	cache := ugulru.NewInMemoryCache(3, 5*time.Minute, ugulru.WithTTLFromValue[string](func(t token) time.Duration {
+		return time.Until(t.expires)
+	}))
+
+	cache.Put("short", token{value: "a", expires: time.Now().Add(100 * time.Millisecond)})
+	cache.Put("long", token{value: "b", expires: time.Now().Add(time.Hour)})
+	cache.Put("expired", token{value: "c", expires: time.Now().Add(-time.Second)})
+
+	_, ok := cache.Get("expired")
+	assert.False(t, ok)
+
+	_, expiry, ok := cache.GetWithExpiry("long")
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expiry, time.Second)
+
+	_, ok = cache.Get("short")
+	assert.True(t, ok)
+	time.Sleep(150 * time.Millisecond)
+	_, ok = cache.Get("short")
+	assert.False(t, ok)
+}
+
+func TestWithWarmupRamp(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(3, time.Hour, ugulru.WithWarmupRamp[string, int](time.Second, 0.1))
+
+	cache.Put("early", 1)
+	_, expiry, ok := cache.GetWithExpiry("early")
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(6*time.Minute), expiry, time.Minute)
+
+	time.Sleep(time.Second)
+	cache.Put("late", 2)
+	_, expiry, ok = cache.GetWithExpiry("late")
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expiry, time.Second)
+}
@@ -0,0 +1,183 @@
+package ugulru
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"time"
+)
+
+// WithOverflow adds a second, larger tier to the cache, such as a DiskStore: entries evicted to make room for new ones
+// are written to the given store, and Load and LoadContext read them back from the store on a miss before calling the
+// loader, so the effective cache is much larger than what fits in memory. Entries read back from the store are moved
+// to memory with the time they had left to live.
+//
+// The cache keeps an index of the keys it wrote to the store, with their expiry, so misses for other keys never hit the
+// store, and writing or removing a key deletes its stale copy from the store. Expired copies are deleted by
+// RemoveExpired. The store is accessed with the lock of the cache held, and store errors are treated as misses.
+func WithOverflow[K comparable, V any](store Store[K, V]) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.overflow = store
+		c.overflowed = make(map[K]spilled)
+	}
+}
+
+// StreamStore is a Store that can also stream encoded values without holding them in memory, such as DiskStore.
+type StreamStore[K comparable, V any] interface {
+	Store[K, V]
+	// PutReader stores the size bytes read from r as the encoded value for the given key.
+	PutReader(key K, r io.Reader, size int64) error
+	// GetReader returns a reader streaming the encoded value stored for the given key, and whether it was found.
+	GetReader(key K) (io.ReadCloser, bool)
+}
+
+// PutReader streams the size bytes read from r as the encoded value for the given key to the overflow store set by
+// WithOverflow, which must implement StreamStore, so large values can be cached without materializing them in memory.
+// The entry replaces the one in memory, if any, lives for the TTL of the cache, and is read back from the store on a
+// miss like the evicted entries. PutReader returns an error if the cache is quiesced, or if it uses WithWriteThrough or
+// WithWriteBehind, since the value would not reach their store. The store is written with the lock of the cache held.
+func (c *InMemoryCache[K, V]) PutReader(key K, r io.Reader, size int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stream, ok := c.overflow.(StreamStore[K, V])
+	switch {
+	case !ok:
+		return errors.New("ugulru: the overflow store can't stream values")
+	case c.quiesced:
+		return errors.New("ugulru: the cache is quiesced")
+	case c.backing != nil:
+		return errors.New("ugulru: streamed values can't be written through to the store")
+	}
+	c.remove(key)
+	if err := stream.PutReader(key, r, size); err != nil {
+		return err
+	}
+	c.overflowed[key] = spilled{expires: c.clock() + c.ttl.Milliseconds()}
+	return nil
+}
+
+// GetReader returns a reader streaming the encoded value for the given key from the overflow store, if it holds a live
+// copy, and whether it was found. The entry is not moved to memory. If the entry is in memory instead, its value is
+// encoded with the codec set by WithCodec, if any.
+func (c *InMemoryCache[K, V]) GetReader(key K) (io.ReadCloser, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.cache[key]; ok {
+		if c.codec == nil || c.expired(e) || e.err != nil {
+			return nil, false
+		}
+		data, err := c.codec.Encode(e.value)
+		if err != nil {
+			return nil, false
+		}
+		return io.NopCloser(bytes.NewReader(data)), true
+	}
+	stream, ok := c.overflow.(StreamStore[K, V])
+	if !ok || !c.overflowedLive(key) {
+		return nil, false
+	}
+	return stream.GetReader(key)
+}
+
+// spilled describes an entry written to the overflow store.
+type spilled struct {
+	expires int64 // milliseconds since the epoch of the cache
+	tags    []string
+}
+
+// writeOverflow writes the given entry, which is being evicted, to the overflow store if it is live. It must be called
+// with the lock held.
+func (c *InMemoryCache[K, V]) writeOverflow(entry *entry[K, V]) {
+	if c.expired(entry) || entry.err != nil {
+		return
+	}
+	if err := c.overflow.Set(context.Background(), entry.key, entry.value); err != nil {
+		return
+	}
+	c.overflowed[entry.key] = spilled{expires: c.expiresAt(entry), tags: slices.Clone(entry.tags)}
+}
+
+// readOverflow moves the value for the given key from the overflow store back to the cache, if the store holds a live
+// copy of it. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) readOverflow(key K) (V, bool) {
+	var zero V
+	if c.overflow == nil || c.quiesced {
+		return zero, false
+	}
+	spill, ok := c.overflowed[key]
+	if !ok {
+		return zero, false
+	}
+	if spill.expires <= c.clock() {
+		c.dropOverflow(key)
+		return zero, false
+	}
+	value, err := c.overflow.Get(context.Background(), key)
+	if err != nil {
+		c.dropOverflow(key)
+		return zero, false
+	}
+	entry := c.put(key, value)
+	entry.ttl = time.Duration(spill.expires-entry.timestamp) * time.Millisecond
+	c.tag(entry, spill.tags)
+	return value, true
+}
+
+// dropOverflow deletes the copy of the given key from the overflow store, if any. It must be called with the lock
+// held.
+func (c *InMemoryCache[K, V]) dropOverflow(key K) {
+	if _, ok := c.overflowed[key]; !ok {
+		return
+	}
+	delete(c.overflowed, key)
+	_ = c.overflow.Delete(context.Background(), key)
+}
+
+// removeExpiredOverflow deletes the expired copies from the overflow store. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) removeExpiredOverflow() {
+	now := c.clock()
+	for key, spill := range c.overflowed {
+		if spill.expires <= now {
+			c.dropOverflow(key)
+		}
+	}
+}
+
+// overflowedLive reports whether the overflow store holds a live copy of the given key. It must be called with the
+// lock held.
+func (c *InMemoryCache[K, V]) overflowedLive(key K) bool {
+	spill, ok := c.overflowed[key]
+	return ok && spill.expires > c.clock()
+}
+
+// overflowedIf returns the keys of the live copies in the overflow store for which the predicate returns true. Their
+// values are read from the store, and copies that can't be read are skipped. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) overflowedIf(predicate func(key K, value V) bool) []K {
+	var keys []K
+	for key := range c.overflowed {
+		if !c.overflowedLive(key) {
+			continue
+		}
+		value, err := c.overflow.Get(context.Background(), key)
+		if err == nil && predicate(key, value) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// overflowedTagged returns the keys of the live copies in the overflow store carrying the given tag. It must be called
+// with the lock held.
+func (c *InMemoryCache[K, V]) overflowedTagged(tag string) []K {
+	var keys []K
+	for key, spill := range c.overflowed {
+		if c.overflowedLive(key) && slices.Contains(spill.tags, tag) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,145 @@
+package ugulru_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithOverflow(t *testing.T) {
+	store, err := ugulru.NewDiskStore[string, int](t.TempDir(), ugulru.StringKeyCodec[string]{}, ugulru.GobCodec[int]{})
+	require.NoError(t, err)
+	cache := ugulru.NewInMemoryCache(2, 5*time.Minute, ugulru.WithOverflow[string, int](store))
+
+	loads := 0
+	loader := func(key string) func() (int, error) {
+		return func() (int, error) {
+			loads++
+			return len(key), nil
+		}
+	}
+
+	cache.Put("a", 1)
+	cache.Put("bb", 2)
+	cache.Put("ccc", 3) // evicts a to the store
+
+	_, ok := cache.Get("a")
+	assert.False(t, ok, "Get should not read the store")
+
+	value, err := cache.Load("a", loader("a"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, value, "a should be read back from the store")
+	assert.Zero(t, loads)
+
+	_, expiry, ok := cache.GetWithExpiry("a")
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), expiry, time.Second)
+
+	// Removing a key deletes its copy from the store
+	cache.Put("dddd", 4) // evicts bb to the store
+	cache.Remove("bb")
+	value, err = cache.Load("bb", loader("bb"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, value)
+	assert.Equal(t, 1, loads)
+}
+
+func TestWithOverflow_Expired(t *testing.T) {
+	store, err := ugulru.NewDiskStore[string, int](t.TempDir(), ugulru.StringKeyCodec[string]{}, ugulru.GobCodec[int]{})
+	require.NoError(t, err)
+	cache := ugulru.NewInMemoryCache(1, 50*time.Millisecond, ugulru.WithOverflow[string, int](store))
+
+	cache.Put("key1", 1)
+	cache.Put("key2", 2) // evicts key1 to the store
+	time.Sleep(100 * time.Millisecond)
+	cache.RemoveExpired()
+
+	_, err = cache.Load("key1", func() (int, error) {
+		return 0, errors.New("not found")
+	})
+	assert.Error(t, err, "expired entries should not be read back from the store")
+}
+
+func TestWithOverflow_Removals(t *testing.T) {
+	store, err := ugulru.NewDiskStore[string, int](t.TempDir(), ugulru.StringKeyCodec[string]{}, ugulru.GobCodec[int]{})
+	require.NoError(t, err)
+	cache := ugulru.NewInMemoryCache(1, 5*time.Minute, ugulru.WithOverflow[string, int](store))
+	notFound := func() (int, error) {
+		return 0, errors.New("not found")
+	}
+	spill := func(key string, value int, tags ...string) {
+		cache.PutTagged(key, value, tags...)
+		cache.Put("filler", 0) // evicts key to the store
+	}
+
+	spill("a", 1)
+	assert.Equal(t, 1, cache.RemoveMany([]string{"a"}))
+	_, err = cache.Load("a", notFound)
+	assert.Error(t, err, "RemoveMany should delete the copy from the store")
+
+	spill("b", 2)
+	assert.Equal(t, 1, cache.RemoveIf(func(key string, value int) bool {
+		return value == 2
+	}))
+	_, err = cache.Load("b", notFound)
+	assert.Error(t, err, "RemoveIf should delete the copy from the store")
+
+	spill("c", 3, "tag")
+	assert.Equal(t, 1, cache.InvalidateTag("tag"))
+	_, err = cache.Load("c", notFound)
+	assert.Error(t, err, "InvalidateTag should delete the copy from the store")
+
+	// Tags survive a round trip through the store
+	spill("d", 4, "tag")
+	value, err := cache.Load("d", notFound)
+	require.NoError(t, err)
+	assert.Equal(t, 4, value)
+	assert.Equal(t, 1, cache.InvalidateTag("tag"))
+
+	spill("e", 5)
+	cache.PutMulti(map[string]int{"e": 6})
+	_, err = store.Get(context.Background(), "e")
+	assert.ErrorIs(t, err, ugulru.ErrNotFound, "PutMulti should delete the stale copy from the store")
+}
+
+func TestWithOverflow_Reader(t *testing.T) {
+	store, err := ugulru.NewDiskStore[string, []byte](t.TempDir(), ugulru.StringKeyCodec[string]{}, ugulru.BytesCodec[[]byte]{})
+	require.NoError(t, err)
+	cache := ugulru.NewInMemoryCache(2, 5*time.Minute, ugulru.WithOverflow[string, []byte](store))
+
+	cache.Put("blob", []byte("old"))
+	blob := bytes.Repeat([]byte("x"), 1<<20)
+	require.NoError(t, cache.PutReader("blob", bytes.NewReader(blob), int64(len(blob))))
+	_, ok := cache.Get("blob")
+	assert.False(t, ok, "the streamed value should replace the one in memory")
+
+	r, ok := cache.GetReader("blob")
+	require.True(t, ok)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, blob, data)
+
+	// The streamed value is read back on a miss like an evicted entry
+	value, err := cache.Load("blob", func() ([]byte, error) {
+		return nil, errors.New("the value should be read from the store")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, blob, value)
+
+	cache.Remove("blob")
+	_, ok = cache.GetReader("blob")
+	assert.False(t, ok)
+	_, err = store.Get(context.Background(), "blob")
+	assert.ErrorIs(t, err, ugulru.ErrNotFound)
+
+	plain := ugulru.NewInMemoryCache[string, []byte](2, time.Minute)
+	assert.Error(t, plain.PutReader("blob", bytes.NewReader(blob), int64(len(blob))))
+}
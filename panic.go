@@ -0,0 +1,37 @@
+package ugulru
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is returned by the loading methods when the loader panics. The cache is left in a consistent state and
+// the panic doesn't propagate to the caller.
+type PanicError struct {
+	// Value is the value the loader panicked with.
+	Value any
+	// Stack is the stack trace of the goroutine at the time of the panic.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("ugulru: loader panicked: %v", e.Value)
+}
+
+// Unwrap returns the value the loader panicked with if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// protect calls the given loader and converts a panic into a *PanicError.
+func protect[V any](ctx context.Context, loader func(ctx context.Context) (V, error)) (value V, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			var zero V
+			value, err = zero, &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return loader(ctx)
+}
@@ -0,0 +1,54 @@
+package ugulru_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoaderPanic(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	t.Run("Test Load", func(t *testing.T) {
+		cache := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+		_, err := cache.Load("key1", func() (int, error) {
+			panic("boom")
+		})
+		var panicErr *ugulru.PanicError
+		assert.ErrorAs(t, err, &panicErr)
+		assert.Equal(t, "boom", panicErr.Value)
+		assert.NotEmpty(t, panicErr.Stack)
+
+		// The cache is still usable
+		value, err := cache.Load("key1", func() (int, error) { return 1, nil })
+		assert.NoError(t, err)
+		assert.Equal(t, 1, value)
+	})
+
+	t.Run("Test LoadContext with a shared load", func(t *testing.T) {
+		cache := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+		_, err := cache.LoadContext(context.Background(), "key1", func(ctx context.Context) (int, error) {
+			panic(errBoom)
+		})
+		assert.ErrorIs(t, err, errBoom)
+
+		// The in-flight load was cleaned up
+		value, err := cache.LoadContext(context.Background(), "key1", func(ctx context.Context) (int, error) {
+			return 1, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, value)
+	})
+
+	t.Run("Test loader running with a timeout", func(t *testing.T) {
+		cache := ugulru.NewInMemoryCache(2, 5*time.Minute, ugulru.WithLoadTimeout[string, int](time.Second))
+		_, err := cache.Load("key1", func() (int, error) {
+			panic(errBoom)
+		})
+		assert.ErrorIs(t, err, errBoom)
+	})
+}
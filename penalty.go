@@ -0,0 +1,11 @@
+package ugulru
+
+// WithMissPenaltyEviction makes eviction take into account how expensive entries are to rebuild. Instead of always
+// evicting the least recently used entry, the cache evicts the entry with the lowest miss penalty among the given
+// number of least recently used entries. The miss penalty of an entry is the time it took Load or LoadChain to load
+// it; entries written with Put have no known penalty and are evicted first.
+func WithMissPenaltyEviction[K comparable, V any](window int) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.penaltyWindow = window
+	}
+}
@@ -0,0 +1,32 @@
+package ugulru_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMissPenaltyEviction(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(3, 5*time.Minute, ugulru.WithMissPenaltyEviction[string, int](2))
+	slowLoader := func() (int, error) {
+		time.Sleep(20 * time.Millisecond)
+		return 1, nil
+	}
+	fastLoader := func() (int, error) {
+		return 2, nil
+	}
+
+	cache.Load("slow", slowLoader)
+	cache.Load("fast", fastLoader)
+	cache.Load("other", slowLoader)
+
+	// "slow" is the least recently used, but "fast" is cheaper to rebuild
+	cache.Put("key4", 4)
+	_, ok := cache.Get("fast")
+	assert.False(t, ok, "fast should be evicted")
+	_, ok = cache.Get("slow")
+	assert.True(t, ok)
+
+}
@@ -0,0 +1,98 @@
+package ugulru
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WithSnapshotFile makes the cache write a snapshot to the file at the given path every interval and when it is
+// closed with Close, so a crash loses at most one interval of cache warmth. Snapshots are written to a temporary file
+// that then replaces the previous snapshot, so the file always holds a complete snapshot. A snapshot that fails to be
+// written is retried at the next interval. If the interval is not positive, the snapshot is only written by Close. Use
+// RestoreFile to reload the snapshot on startup.
+func WithSnapshotFile[K comparable, V any](path string, interval time.Duration) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.snapshotPath = path
+		c.snapshotEvery = interval
+	}
+}
+
+// SnapshotFile atomically writes a snapshot of the cache to the file at the given path, replacing the file if it
+// exists.
+func (c *InMemoryCache[K, V]) SnapshotFile(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := c.Snapshot(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// RestoreFile restores the snapshot written to the file at the given path. See Restore.
+func (c *InMemoryCache[K, V]) RestoreFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return c.Restore(f)
+}
+
+// Close stops the background work of the cache started by its options. With WithWriteBehind, it first waits for the
+// queued writes to be sent to the store, and with WithSnapshotFile, it writes a final snapshot. The cache remains
+// usable after Close. Calling Close more than once has no effect.
+func (c *InMemoryCache[K, V]) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		if c.behind != nil {
+			_ = c.Flush(context.Background())
+		}
+		if c.snapshotPath == "" {
+			return
+		}
+		if c.closed != nil {
+			close(c.closed)
+			<-c.snapshotsDone
+		}
+		err = c.SnapshotFile(c.snapshotPath)
+	})
+	return err
+}
+
+// startSnapshots starts writing periodic snapshots if WithSnapshotFile is used with a positive interval.
+func (c *InMemoryCache[K, V]) startSnapshots() {
+	if c.snapshotPath == "" || c.snapshotEvery <= 0 {
+		return
+	}
+	c.closed = make(chan struct{})
+	c.snapshotsDone = make(chan struct{})
+	go func() {
+		defer close(c.snapshotsDone)
+
+		ticker := time.NewTicker(c.snapshotEvery)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_ = c.SnapshotFile(c.snapshotPath)
+			case <-c.closed:
+				return
+			}
+		}
+	}()
+}
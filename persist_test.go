@@ -0,0 +1,70 @@
+package ugulru_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithSnapshotFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	cache := ugulru.NewInMemoryCache(3, 5*time.Minute, ugulru.WithSnapshotFile[string, int](path, 50*time.Millisecond))
+	cache.Put("key1", 1)
+
+	assert.Eventually(t, func() bool {
+		restored := ugulru.NewInMemoryCache[string, int](3, time.Minute)
+		return restored.RestoreFile(path) == nil && slices.Equal(slices.Collect(restored.Keys()), []string{"key1"})
+	}, time.Second, 10*time.Millisecond)
+
+	cache.Put("key2", 2)
+	require.NoError(t, cache.Close())
+	require.NoError(t, cache.Close())
+
+	restored := ugulru.NewInMemoryCache[string, int](3, time.Minute)
+	require.NoError(t, restored.RestoreFile(path))
+	assert.Equal(t, []string{"key2", "key1"}, slices.Collect(restored.Keys()))
+
+	// No temporary files are left behind
+	files, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestInMemoryCache_RestoreFile_Missing(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, time.Minute)
+	assert.ErrorIs(t, cache.RestoreFile(filepath.Join(t.TempDir(), "missing")), os.ErrNotExist)
+	assert.NoError(t, cache.Close())
+}
+
+func TestWithSnapshotFile_NoInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	cache := ugulru.NewInMemoryCache(3, 5*time.Minute, ugulru.WithSnapshotFile[string, int](path, 0))
+	cache.Put("key1", 1)
+	_, err := os.Stat(path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, cache.Close())
+	restored := ugulru.NewInMemoryCache[string, int](3, time.Minute)
+	require.NoError(t, restored.RestoreFile(path))
+	assert.Equal(t, []string{"key1"}, slices.Collect(restored.Keys()))
+}
+
+func TestInMemoryCache_Close_WriteBehind(t *testing.T) {
+	store := newMapStore[string, int]()
+	cache := ugulru.NewInMemoryCache(3, 5*time.Minute, ugulru.WithWriteBehind[string, int](store, ugulru.WriteBehindConfig[string]{
+		Interval: time.Hour,
+	}))
+	cache.Put("key1", 1)
+	cache.Remove("key2")
+
+	require.NoError(t, cache.Close())
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	assert.Equal(t, map[string]int{"key1": 1}, store.data)
+}
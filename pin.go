@@ -0,0 +1,45 @@
+package ugulru
+
+// Pin makes the entry with the given key resident until it is unpinned: it is skipped by capacity eviction and never
+// expires. It can still be removed explicitly or replaced. Pin returns false if the key doesn't exist in the cache or
+// has already expired. If all the entries are pinned, the cache grows beyond its capacity.
+func (c *InMemoryCache[K, V]) Pin(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.cache[key]
+	if !ok {
+		return false
+	}
+	if c.expired(entry) {
+		return false
+	}
+	entry.pinned = true
+	return true
+}
+
+// Unpin makes the entry with the given key subject to eviction and expiry again. It returns false if the key doesn't
+// exist in the cache.
+func (c *InMemoryCache[K, V]) Unpin(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.cache[key]
+	if !ok {
+		return false
+	}
+	e.pinned = false
+	if !c.quiesced {
+		c.trim()
+	}
+	return true
+}
+
+// evictable returns the first unpinned entry starting from the given one towards the most recently used one, or nil
+// if there is none. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) evictable(e *entry[K, V]) *entry[K, V] {
+	for e != nil && e.pinned {
+		e = e.Prev()
+	}
+	return e
+}
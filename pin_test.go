@@ -0,0 +1,54 @@
+package ugulru_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryCache_Pin(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](2, 100*time.Millisecond)
+	assert.False(t, cache.Pin("config"))
+
+	cache.Put("config", 1)
+	assert.True(t, cache.Pin("config"))
+	cache.Put("key1", 2)
+	cache.Put("key2", 3) // evicts key1 rather than the least recently used config
+
+	_, ok := cache.Get("key1")
+	assert.False(t, ok)
+	_, ok = cache.Get("key2")
+	assert.True(t, ok)
+
+	time.Sleep(150 * time.Millisecond)
+	value, ok := cache.Get("config")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	_, ok = cache.Get("key2")
+	assert.False(t, ok)
+
+	assert.True(t, cache.Unpin("config"))
+	_, ok = cache.Get("config")
+	assert.False(t, ok)
+	assert.False(t, cache.Unpin("config"))
+}
+
+func TestInMemoryCache_Pin_AllPinned(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](1, 5*time.Minute)
+	cache.Put("key1", 1)
+	cache.Pin("key1")
+	cache.Put("key2", 2)
+	cache.Pin("key2")
+	cache.Put("key3", 3)
+
+	assert.Equal(t, 3, cache.Stats().Len)
+	cache.Resize(1) // evicts key3, the only unpinned entry
+	assert.Equal(t, 2, cache.Stats().Len)
+
+	cache.Unpin("key1")
+	assert.Equal(t, 1, cache.Stats().Len)
+	_, ok := cache.Get("key1")
+	assert.False(t, ok)
+}
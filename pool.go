@@ -0,0 +1,28 @@
+package ugulru
+
+// maxFree is the maximum number of removed entries kept for reuse. It is enough for steady-state churn, where every
+// insert follows an eviction, and for moderate bursts of removals, without pinning much memory.
+const maxFree = 256
+
+// newEntry returns a zeroed entry, reusing a removed one if possible. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) newEntry() *entry[K, V] {
+	n := len(c.free)
+	if n == 0 {
+		return new(entry[K, V])
+	}
+	e := c.free[n-1]
+	c.free[n-1] = nil
+	c.free = c.free[:n-1]
+	return e
+}
+
+// recycle keeps the given removed entry for reuse by newEntry, clearing it so it doesn't retain its key and value.
+// Entries that are being refreshed in the background are still referenced by the refresh and are left to the garbage
+// collector. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) recycle(e *entry[K, V]) {
+	if e.refreshing || len(c.free) >= maxFree {
+		return
+	}
+	*e = entry[K, V]{}
+	c.free = append(c.free, e)
+}
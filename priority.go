@@ -0,0 +1,34 @@
+package ugulru
+
+import "context"
+
+// PutWithPriority works like Put but sets the priority of the entry. Once an entry has been written with a priority,
+// eviction removes the least recently used entry among those with the lowest priority, so cheap-to-recompute entries
+// are sacrificed before expensive ones. Entries written with Put have priority 0. Finding the victim then takes time
+// proportional to the number of entries.
+func (c *InMemoryCache[K, V]) PutWithPriority(key K, value V, priority int) {
+	c.throttle()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, _ = c.writeThrough(context.Background(), key, value, func(entry *entry[K, V]) {
+		entry.priority = priority
+		c.prioritized = true
+	})
+}
+
+// lowestPriority returns the least recently used unpinned entry among those with the lowest priority, or nil if all
+// the elements are pinned. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) lowestPriority() *entry[K, V] {
+	victim := c.evictable(c.list.Back())
+	if victim == nil {
+		return nil
+	}
+	priority := victim.priority
+	for e := c.evictable(victim.Prev()); e != nil; e = c.evictable(e.Prev()) {
+		if p := e.priority; p < priority {
+			victim, priority = e, p
+		}
+	}
+	return victim
+}
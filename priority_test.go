@@ -0,0 +1,30 @@
+package ugulru_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryCache_PutWithPriority(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+
+	cache.PutWithPriority("expensive", 1, 10)
+	cache.PutWithPriority("cheap1", 2, 1)
+	cache.PutWithPriority("cheap2", 3, 1)
+
+	cache.Put("key", 4) // evicts cheap1, the least recently used among the lowest priority entries
+	_, ok := cache.Get("cheap1")
+	assert.False(t, ok)
+
+	cache.Put("key2", 5) // evicts key, which has the default priority 0
+	_, ok = cache.Get("key")
+	assert.False(t, ok)
+
+	for _, key := range []string{"expensive", "cheap2", "key2"} {
+		_, ok := cache.Get(key)
+		assert.True(t, ok, key)
+	}
+}
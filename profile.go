@@ -0,0 +1,40 @@
+package ugulru
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Profile is a compact summary of how a cache was used: the number of entries it held. It is meant to be persisted when
+// a service shuts down and passed to WithProfile on the next startup, so the map of entries of the new cache is
+// allocated for the number of entries it is going to hold instead of growing from scratch.
+type Profile struct {
+	// Len is the number of entries the cache held.
+	Len int `json:"len"`
+}
+
+// Profile returns the usage profile of the cache.
+func (c *InMemoryCache[K, V]) Profile() Profile {
+	return Profile{Len: c.Stats().Len}
+}
+
+// WriteProfile writes the given profile to w as JSON.
+func WriteProfile(w io.Writer, p Profile) error {
+	return json.NewEncoder(w).Encode(p)
+}
+
+// ReadProfile reads a profile written by WriteProfile from r.
+func ReadProfile(r io.Reader) (Profile, error) {
+	var p Profile
+	err := json.NewDecoder(r).Decode(&p)
+	return p, err
+}
+
+// WithProfile pre-sizes the map of entries of the cache for the number of entries recorded in the given profile,
+// capped at the capacity of the new cache, which avoids rehashing while the cache warms up. It only affects this
+// allocation: the capacity, TTL, eviction policy and buffers of the cache are configured by the other options.
+func WithProfile[K comparable, V any](p Profile) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.sizeHint = min(p.Len, c.capacity)
+	}
+}
@@ -0,0 +1,32 @@
+package ugulru_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfile(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](4, 5*time.Minute)
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+	cache.Get("key1")
+	cache.Get("key3")
+
+	var buf bytes.Buffer
+	assert.NoError(t, ugulru.WriteProfile(&buf, cache.Profile()))
+
+	profile, err := ugulru.ReadProfile(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, ugulru.Profile{Len: 2}, profile)
+
+	// The profile only affects the internal sizing of the new cache
+	cache = ugulru.NewInMemoryCache(2, 5*time.Minute, ugulru.WithProfile[string, int](profile))
+	cache.Put("key1", 1)
+	value, ok := cache.Get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+}
@@ -0,0 +1,28 @@
+package ugulru
+
+// WithPromotionThreshold reduces the list churn of hits: a hit only moves the entry to the front of the LRU list if it
+// has fallen out of the given fraction of the most recently used entries (e.g. 0.25 for the top 25%), and entries that
+// are already near the front stay where they are. The position of an entry is bounded by the number of entries
+// written or moved to the front since it was last moved, so the check needs no list traversal.
+func WithPromotionThreshold[K comparable, V any](fraction float64) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.promoteAfter = fraction
+	}
+}
+
+// bump moves the given entry hit by a read to the front of the list, unless it is still near the front. It must be
+// called with the lock held.
+func (c *InMemoryCache[K, V]) bump(e *entry[K, V]) {
+	if c.promoteAfter > 0 && float64(c.moves-e.moved) < c.promoteAfter*float64(c.list.Len()) {
+		return
+	}
+	c.list.MoveToFront(e)
+	e.moved = c.nextMove()
+}
+
+// nextMove returns the sequence number of the next move of an entry to the front of the list. It must be called with
+// the lock held.
+func (c *InMemoryCache[K, V]) nextMove() uint64 {
+	c.moves++
+	return c.moves
+}
@@ -0,0 +1,34 @@
+package ugulru_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithPromotionThreshold(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(4, 5*time.Minute, ugulru.WithPromotionThreshold[string, int](0.5))
+
+	for i := range 4 {
+		cache.Put(fmt.Sprintf("key%d", i), i)
+	}
+
+	// key2 is in the top half, so the hit doesn't move it in front of key3
+	cache.Get("key2")
+	// key0 is in the bottom half, so the hit moves it to the front
+	cache.Get("key0")
+
+	cache.Put("key4", 4) // evicts key1
+	cache.Put("key5", 5) // evicts key2
+	_, ok := cache.Get("key1")
+	assert.False(t, ok)
+	_, ok = cache.Get("key2")
+	assert.False(t, ok)
+	for _, key := range []string{"key0", "key3", "key4", "key5"} {
+		_, ok := cache.Get(key)
+		assert.True(t, ok, key)
+	}
+}
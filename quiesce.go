@@ -0,0 +1,69 @@
+package ugulru
+
+// maxPending is the number of writes buffered while the cache is quiesced at which the cache is unquiesced.
+const maxPending = 1 << 16
+
+// pendingWrite is a write operation buffered while the cache is quiesced, with the function setting the metadata of
+// the written entry, such as its TTL or tags, if any.
+type pendingWrite[K comparable, V any] struct {
+	key    K
+	value  V
+	apply  func(*entry[K, V])
+	remove bool
+}
+
+// Quiesce freezes the contents of the cache so that a snapshot or a migration can read a stable view of it without
+// holding the lock for the whole time. While the cache is quiesced:
+//
+//   - Put, Remove, RemoveMany and values stored by Load are buffered instead of being applied, so reads keep
+//     returning the values that were in the cache when it was quiesced;
+//   - reads don't change the recency of entries and don't remove expired entries;
+//   - RemoveExpired does nothing and Resize doesn't evict entries.
+//
+// Once 65536 writes are buffered, the cache is unquiesced, so a forgotten Unquiesce can't make the buffer grow without
+// bound.
+//
+// Calling Quiesce on a quiesced cache has no effect.
+func (c *InMemoryCache[K, V]) Quiesce() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.quiesced = true
+}
+
+// Unquiesce replays the writes buffered since Quiesce was called, in the order they were made, and resumes normal
+// operation. Calling Unquiesce on a cache that is not quiesced has no effect.
+func (c *InMemoryCache[K, V]) Unquiesce() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.unquiesce()
+}
+
+// unquiesce replays the buffered writes and resumes normal operation. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) unquiesce() {
+	if !c.quiesced {
+		return
+	}
+	c.quiesced = false
+
+	for _, w := range c.pending {
+		if w.remove {
+			c.remove(w.key)
+		} else {
+			c.store(w.key, w.value, w.apply)
+		}
+	}
+	c.pending = nil
+
+	c.trim()
+}
+
+// buffer records a write to be replayed by Unquiesce, or replays all the buffered writes if there are too many. It
+// must be called with the lock held.
+func (c *InMemoryCache[K, V]) buffer(w pendingWrite[K, V]) {
+	c.pending = append(c.pending, w)
+	if len(c.pending) >= maxPending {
+		c.unquiesce()
+	}
+}
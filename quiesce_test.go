@@ -0,0 +1,89 @@
+package ugulru_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryCache_Quiesce(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+
+	cache.Quiesce()
+
+	// Writes are buffered
+	cache.Put("key1", 10)
+	cache.Put("key3", 3)
+	cache.Remove("key2")
+	value, err := cache.Load("key4", func() (int, error) { return 4, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 4, value)
+
+	// Reads see the frozen view and don't change the recency
+	value, ok := cache.Get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	value, ok = cache.Get("key2")
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+	_, ok = cache.Get("key3")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"key2", "key1"}, slices.Collect(cache.Keys()))
+
+	cache.Unquiesce()
+
+	// Buffered writes are replayed in order
+	_, ok = cache.Get("key2")
+	assert.False(t, ok)
+	_, ok = cache.Get("key1")
+	assert.False(t, ok, "key1 should be evicted by key3 and key4")
+	value, ok = cache.Get("key3")
+	assert.True(t, ok)
+	assert.Equal(t, 3, value)
+	value, ok = cache.Get("key4")
+	assert.True(t, ok)
+	assert.Equal(t, 4, value)
+
+	// Writes are applied immediately again
+	cache.Put("key5", 5)
+	value, ok = cache.Get("key5")
+	assert.True(t, ok)
+	assert.Equal(t, 5, value)
+}
+
+func TestInMemoryCache_QuiesceMetadata(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](10, 5*time.Minute)
+	deadline := time.Now().Add(time.Hour)
+
+	cache.Quiesce()
+	cache.PutWithDeadline("deadline", 1, deadline)
+	cache.PutWithTTL("ttl", 2, 0, time.Minute)
+	cache.PutTagged("tagged", 3, "tag")
+	cache.Unquiesce()
+
+	_, expiry, ok := cache.GetWithExpiry("deadline")
+	assert.True(t, ok)
+	assert.WithinDuration(t, deadline, expiry, time.Second)
+	_, expiry, ok = cache.GetWithExpiry("ttl")
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expiry, time.Second)
+	assert.Equal(t, 1, cache.InvalidateTag("tag"))
+}
+
+func TestInMemoryCache_QuiesceBounded(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[int, int](10, 5*time.Minute)
+	cache.Put(0, 0)
+
+	cache.Quiesce()
+	for i := range 1 << 16 {
+		cache.Put(0, i+1)
+	}
+	value, ok := cache.Get(0)
+	assert.True(t, ok)
+	assert.Equal(t, 1<<16, value, "the cache should be unquiesced once the buffer is full")
+}
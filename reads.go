@@ -0,0 +1,72 @@
+package ugulru
+
+import "sync/atomic"
+
+// WithSharedReads lets hits run concurrently under a shared read lock instead of the exclusive lock, for read-heavy
+// workloads. A hit only records the entry in a lossy buffer with atomic operations; the recorded hits are applied to
+// the LRU order and access frequencies in a batch by the next write, so the order is slightly approximate and some
+// hits may not be recorded at all under heavy contention. Expired entries are only removed by writes. The option has
+// no effect when it is combined with a feature that must update the cache on every hit: WithSlidingExpiration,
+// WithExpireAfterAccess, WithRefreshAhead, WithKeyGroups, WithSampling, WithTinyLFU or WithGhostCaches.
+func WithSharedReads[K comparable, V any]() Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.sharedReads = true
+	}
+}
+
+// sharedReadsSupported reports whether the configured features allow hits under the shared read lock.
+func (c *InMemoryCache[K, V]) sharedReadsSupported() bool {
+	return !c.sliding && c.tti == 0 && c.refreshLoader == nil && c.group == nil && c.sampler == nil &&
+		c.sketch == nil && len(c.ghosts) == 0
+}
+
+// readBufferSize is the number of hits recorded between two writes; further hits are dropped.
+const readBufferSize = 256
+
+// readBuffer records the entries hit under the shared read lock.
+type readBuffer[K comparable, V any] struct {
+	pos     atomic.Uint64
+	entries [readBufferSize]atomic.Pointer[entry[K, V]]
+}
+
+// getShared is the implementation of Get under the shared read lock.
+func (c *InMemoryCache[K, V]) getShared(key K) (V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.cache[key]
+	if !ok || c.expired(entry) || entry.err != nil {
+		c.misses.Add(1)
+		var zero V
+		return zero, false
+	}
+	c.hits.Add(1)
+	if i := c.reads.pos.Add(1) - 1; i < readBufferSize {
+		c.reads.entries[i].Store(entry)
+	}
+	return entry.value, true
+}
+
+// drainReads applies the hits recorded by getShared. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) drainReads() {
+	if c.reads == nil {
+		return
+	}
+	n := min(c.reads.pos.Load(), readBufferSize)
+	now := c.clock()
+	for i := range n {
+		e := c.reads.entries[i].Swap(nil)
+		// Entries removed since the hit are not in the list anymore. An entry recycled for another key since then is
+		// promoted instead, which only makes the order slightly less accurate.
+		if e == nil || e.root != &c.list.root || c.quiesced {
+			continue
+		}
+		c.bump(e)
+		e.accessed = now
+		c.touchFrequency(e, now)
+		if c.protectedShare > 0 {
+			c.promote(e)
+		}
+	}
+	c.reads.pos.Store(0)
+}
@@ -0,0 +1,62 @@
+package ugulru_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSharedReads(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(2, 100*time.Millisecond, ugulru.WithSharedReads[string, int]())
+
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+	value, ok := cache.Get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	// The hit on key1 is applied by the next write, so key2 is evicted
+	cache.Put("key3", 3)
+	_, ok = cache.Get("key2")
+	assert.False(t, ok)
+	_, ok = cache.Get("key1")
+	assert.True(t, ok)
+
+	time.Sleep(150 * time.Millisecond)
+	_, ok = cache.Get("key1")
+	assert.False(t, ok)
+
+	stats := cache.Stats()
+	assert.Equal(t, uint64(2), stats.Hits)
+	assert.Equal(t, uint64(2), stats.Misses)
+}
+
+func TestWithSharedReads_Concurrent(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(100, 5*time.Minute, ugulru.WithSharedReads[int, int]())
+	for i := range 100 {
+		cache.Put(i, i)
+	}
+
+	var wg sync.WaitGroup
+	for w := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				if w == 0 && i%10 == 0 {
+					cache.Put(100+i, i)
+					continue
+				}
+				if value, ok := cache.Get(i % 100); ok {
+					assert.Equal(t, i%100, value)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 100, cache.Stats().Len)
+}
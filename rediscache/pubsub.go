@@ -0,0 +1,51 @@
+package rediscache
+
+import (
+	"context"
+
+	"github.com/machine23/ugulru"
+	"github.com/redis/go-redis/v9"
+)
+
+// PubSub is a ugulru.PubSub over a Redis pub/sub channel, for use with ugulru.NewInvalidator.
+type PubSub struct {
+	client  redis.UniversalClient
+	channel string
+}
+
+var _ ugulru.PubSub = (*PubSub)(nil)
+
+// NewPubSub creates a new pub/sub over the Redis channel with the given name.
+func NewPubSub(client redis.UniversalClient, channel string) *PubSub {
+	return &PubSub{client: client, channel: channel}
+}
+
+// Publish publishes the given payload to the channel.
+func (p *PubSub) Publish(ctx context.Context, payload []byte) error {
+	return p.client.Publish(ctx, p.channel, payload).Err()
+}
+
+// Subscribe calls handler with every payload published to the channel until unsubscribe is called. Payloads are
+// handled one at a time, in the order they were published.
+func (p *PubSub) Subscribe(handler func(payload []byte)) (func() error, error) {
+	ctx := context.Background()
+	sub := p.client.Subscribe(ctx, p.channel)
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for msg := range sub.Channel() {
+			handler([]byte(msg.Payload))
+		}
+	}()
+	return func() error {
+		err := sub.Close()
+		<-done
+		return err
+	}, nil
+}
@@ -0,0 +1,37 @@
+package rediscache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/machine23/ugulru/rediscache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPubSub(t *testing.T) {
+	_, client := newClient(t)
+	pubsub := rediscache.NewPubSub(client, "invalidations")
+
+	local := ugulru.NewInMemoryCache[string, int](10, time.Minute)
+	remote := ugulru.NewInMemoryCache[string, int](10, time.Minute)
+	local.Put("key1", 1)
+	remote.Put("key1", 1)
+
+	localInvalidator, err := ugulru.NewInvalidator(local, pubsub, ugulru.StringKeyCodec[string]{})
+	require.NoError(t, err)
+	defer localInvalidator.Close()
+	remoteInvalidator, err := ugulru.NewInvalidator(remote, pubsub, ugulru.StringKeyCodec[string]{})
+	require.NoError(t, err)
+	defer remoteInvalidator.Close()
+
+	require.NoError(t, localInvalidator.Remove(context.Background(), "key1"))
+	_, ok := local.Get("key1")
+	assert.False(t, ok)
+	assert.Eventually(t, func() bool {
+		_, ok := remote.Get("key1")
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+}
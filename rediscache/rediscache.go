@@ -0,0 +1,129 @@
+// Package rediscache provides a ugulru.Cache backed by Redis, so code written against ugulru.Cache can switch between
+// in-process and shared caching via configuration. Entries expire using the native TTL support of Redis. The package
+// also provides a ugulru.PubSub over Redis pub/sub, to keep the in-memory caches of several replicas coherent.
+package rediscache
+
+import (
+	"context"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/redis/go-redis/v9"
+)
+
+// Cache is a ugulru.Cache storing its entries in Redis. Keys and values are converted to strings and bytes with the
+// given codecs; use ugulru.PrefixKeyCodec to share a Redis database between several caches. Redis errors are treated
+// as misses by Get and Load, and Put and Remove drop them. It is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	client  redis.UniversalClient
+	ttl     time.Duration
+	keys    ugulru.KeyCodec[K]
+	values  ugulru.Codec[V]
+	timeout time.Duration
+}
+
+var _ ugulru.Cache[string, any] = (*Cache[string, any])(nil)
+
+// Option configures a Cache.
+type Option[K comparable, V any] func(*Cache[K, V])
+
+// WithTimeout bounds the time each Redis command may take. Without it, commands are only bounded by the timeouts of
+// the client.
+func WithTimeout[K comparable, V any](timeout time.Duration) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.timeout = timeout
+	}
+}
+
+// New creates a new cache storing its entries in Redis through the given client with the given time-to-live. A TTL of
+// zero means that entries never expire. The client is owned by the caller, who must close it once the cache is no
+// longer used.
+func New[K comparable, V any](client redis.UniversalClient, ttl time.Duration, keys ugulru.KeyCodec[K], values ugulru.Codec[V], opts ...Option[K, V]) *Cache[K, V] {
+	c := &Cache[K, V]{client: client, ttl: ttl, keys: keys, values: values}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// commandContext returns the context for a single Redis command.
+func (c *Cache[K, V]) commandContext() (context.Context, context.CancelFunc) {
+	if c.timeout > 0 {
+		return context.WithTimeout(context.Background(), c.timeout)
+	}
+	return context.Background(), func() {}
+}
+
+// Get retrieves the value for the given key, if it exists and hasn't expired.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	value, err := c.get(key)
+	return value, err == nil
+}
+
+// get reads and decodes the value for the given key. It returns redis.Nil if the key doesn't exist.
+func (c *Cache[K, V]) get(key K) (V, error) {
+	var value V
+	k, err := c.keys.EncodeKey(key)
+	if err != nil {
+		return value, err
+	}
+	ctx, cancel := c.commandContext()
+	defer cancel()
+
+	data, err := c.client.Get(ctx, k).Bytes()
+	if err != nil {
+		return value, err
+	}
+	return c.values.Decode(data)
+}
+
+// Put stores the value for the given key with the TTL of the cache.
+func (c *Cache[K, V]) Put(key K, value V) {
+	_ = c.put(key, value)
+}
+
+// put encodes and writes the value for the given key.
+func (c *Cache[K, V]) put(key K, value V) error {
+	k, err := c.keys.EncodeKey(key)
+	if err != nil {
+		return err
+	}
+	data, err := c.values.Encode(value)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.commandContext()
+	defer cancel()
+
+	return c.client.Set(ctx, k, data, c.ttl).Err()
+}
+
+// Remove deletes the entry with the given key.
+func (c *Cache[K, V]) Remove(key K) {
+	k, err := c.keys.EncodeKey(key)
+	if err != nil {
+		return
+	}
+	ctx, cancel := c.commandContext()
+	defer cancel()
+
+	_ = c.client.Del(ctx, k).Err()
+}
+
+// RemoveExpired does nothing, since Redis removes expired keys on its own.
+func (c *Cache[K, V]) RemoveExpired() {}
+
+// Load retrieves the value for the given key. If the key doesn't exist or has expired, the loader is called to load
+// the value, which is then stored in Redis and returned. The loaded value is returned even if it can't be stored.
+// Concurrent loads of the same key, in this process or others, are not deduplicated.
+func (c *Cache[K, V]) Load(key K, loader func() (V, error)) (V, error) {
+	if value, err := c.get(key); err == nil {
+		return value, nil
+	}
+	value, err := loader()
+	if err != nil {
+		return value, err
+	}
+	_ = c.put(key, value)
+	return value, nil
+}
@@ -0,0 +1,79 @@
+package rediscache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/machine23/ugulru"
+	"github.com/machine23/ugulru/rediscache"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClient(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		client.Close()
+	})
+	return server, client
+}
+
+func TestCache(t *testing.T) {
+	server, client := newClient(t)
+	codec := ugulru.PrefixKeyCodec[string]{Prefix: "users:", Codec: ugulru.StringKeyCodec[string]{}}
+	cache := rediscache.New[string, int](client, time.Minute, codec, ugulru.JSONCodec[int]{})
+
+	_, ok := cache.Get("key1")
+	assert.False(t, ok)
+
+	cache.Put("key1", 1)
+	value, ok := cache.Get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	stored, err := server.Get("users:key1")
+	require.NoError(t, err)
+	assert.Equal(t, "1", stored)
+	assert.Equal(t, time.Minute, server.TTL("users:key1"))
+
+	cache.Remove("key1")
+	_, ok = cache.Get("key1")
+	assert.False(t, ok)
+
+	loads := 0
+	loader := func() (int, error) {
+		loads++
+		return 2, nil
+	}
+	for range 2 {
+		value, err := cache.Load("key2", loader)
+		require.NoError(t, err)
+		assert.Equal(t, 2, value)
+	}
+	assert.Equal(t, 1, loads)
+
+	server.FastForward(time.Minute)
+	_, ok = cache.Get("key2")
+	assert.False(t, ok)
+}
+
+func TestCache_Unavailable(t *testing.T) {
+	server, client := newClient(t)
+	cache := rediscache.New(client, time.Minute, ugulru.StringKeyCodec[string]{}, ugulru.JSONCodec[int]{},
+		rediscache.WithTimeout[string, int](100*time.Millisecond))
+	server.Close()
+
+	cache.Put("key1", 1)
+	_, ok := cache.Get("key1")
+	assert.False(t, ok)
+
+	value, err := cache.Load("key1", func() (int, error) {
+		return 1, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, value)
+}
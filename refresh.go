@@ -0,0 +1,136 @@
+package ugulru
+
+import (
+	"context"
+	"time"
+)
+
+// WithStaleWhileRevalidate keeps expired entries for the given window after they expire. When Load finds such a stale
+// entry, it returns the stale value immediately and refreshes the entry in the background using its loader, so callers
+// don't block on the loader for keys that are still in use. Other methods treat stale entries as expired.
+func WithStaleWhileRevalidate[K comparable, V any](window time.Duration) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.stale = window
+	}
+}
+
+// WithRefreshAhead makes a hit on an entry that was written more than refreshAfter ago reload the entry in the
+// background using the given loader, so hot entries are kept fresh without ever blocking callers on the loader.
+// refreshAfter should be shorter than the TTL.
+func WithRefreshAhead[K comparable, V any](refreshAfter time.Duration, loader func(key K) (V, error)) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.refreshAfter = refreshAfter
+		c.refreshLoader = loader
+	}
+}
+
+// refreshAhead starts refreshing the given live entry in the background if it is due for a refresh and no refresh is
+// in progress. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) refreshAhead(entry *entry[K, V]) {
+	if c.refreshLoader == nil || entry.refreshing || c.clock()-entry.timestamp <= c.refreshAfter.Milliseconds() {
+		return
+	}
+	entry.refreshing = true
+	key, gen := entry.key, entry.gen
+	c.goBackground(func() {
+		c.refresh(entry, gen, func() (V, error) {
+			return c.refreshLoader(key)
+		})
+	})
+}
+
+// retained reports whether the given expired entry is within a stale window and must be kept in the cache. It must be
+// called with the lock held.
+func (c *InMemoryCache[K, V]) retained(entry *entry[K, V]) bool {
+	return c.withinStale(entry, max(c.stale, c.staleIfError))
+}
+
+// withinStale reports whether the given entry expired less than the given window ago. It must be called with the lock
+// held.
+func (c *InMemoryCache[K, V]) withinStale(entry *entry[K, V], window time.Duration) bool {
+	return window > 0 && c.clock() < c.expiresAt(entry)+window.Milliseconds()
+}
+
+// revalidate returns the stale value for the given key, if any, and starts refreshing it in the background unless a
+// refresh is already in progress. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) revalidate(key K, loader func() (V, error)) (V, bool) {
+	var zero V
+	if c.stale <= 0 || c.quiesced {
+		return zero, false
+	}
+	entry, ok := c.cache[key]
+	if !ok {
+		return zero, false
+	}
+	if entry.err != nil || !c.expired(entry) || !c.withinStale(entry, c.stale) {
+		return zero, false
+	}
+	if !entry.refreshing {
+		entry.refreshing = true
+		gen := entry.gen
+		c.goBackground(func() {
+			c.refresh(entry, gen, loader)
+		})
+	}
+	return entry.value, true
+}
+
+// refresh reloads the value of the given entry. The loaded value is only stored if the entry is still in the cache and
+// hasn't been written since the refresh started.
+func (c *InMemoryCache[K, V]) refresh(entry *entry[K, V], gen uint64, loader func() (V, error)) {
+	value, err := c.callLoader(context.Background(), entry.key, func(context.Context) (V, error) {
+		return loader()
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry.refreshing = false
+	if err != nil || entry.gen != gen {
+		return
+	}
+	if c.cache[entry.key] == entry {
+		c.store(entry.key, value, nil)
+	}
+}
+
+// WithSoftTTL sets a soft TTL, shorter than the TTL of the cache, which then acts as the hard TTL. When Load hits an
+// entry older than the soft TTL, it returns the value and refreshes the entry in the background using its loader;
+// entries older than the hard TTL are never served. Both TTLs can be overridden per entry with PutWithTTL.
+func WithSoftTTL[K comparable, V any](soft time.Duration) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.softTTL = soft
+	}
+}
+
+// PutWithTTL works like Put but sets the soft and the hard TTL of the entry, overriding the ones of the cache. A zero
+// TTL keeps the one of the cache.
+func (c *InMemoryCache[K, V]) PutWithTTL(key K, value V, soft, hard time.Duration) {
+	c.throttle()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, _ = c.writeThrough(context.Background(), key, value, func(entry *entry[K, V]) {
+		entry.soft = soft
+		if hard != 0 {
+			entry.ttl = hard
+		}
+	})
+}
+
+// refreshSoft starts refreshing the given live entry in the background using the given loader if the entry is older
+// than its soft TTL and no refresh is in progress. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) refreshSoft(entry *entry[K, V], loader func() (V, error)) {
+	soft := c.softTTL
+	if entry.soft != 0 {
+		soft = entry.soft
+	}
+	if soft <= 0 || entry.refreshing || c.clock()-entry.timestamp <= soft.Milliseconds() {
+		return
+	}
+	entry.refreshing = true
+	gen := entry.gen
+	c.goBackground(func() {
+		c.refresh(entry, gen, loader)
+	})
+}
@@ -0,0 +1,141 @@
+package ugulru_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithStaleWhileRevalidate(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(2, 100*time.Millisecond, ugulru.WithStaleWhileRevalidate[string, int](time.Second))
+
+	var calls atomic.Int32
+	loader := func() (int, error) {
+		n := calls.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		return int(n), nil
+	}
+
+	value, err := cache.Load("key1", loader)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+
+	time.Sleep(150 * time.Millisecond)
+
+	// The stale value is returned immediately and only one refresh is started
+	start := time.Now()
+	for range 3 {
+		value, err = cache.Load("key1", loader)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, value)
+	}
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	// Get doesn't serve stale values
+	_, ok := cache.Get("key1")
+	assert.False(t, ok)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(2), calls.Load())
+	value, ok = cache.Get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+}
+
+func TestWithStaleWhileRevalidate_OutsideWindow(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(2, 50*time.Millisecond, ugulru.WithStaleWhileRevalidate[string, int](50*time.Millisecond))
+	cache.Put("key1", 1)
+
+	time.Sleep(150 * time.Millisecond)
+	value, err := cache.Load("key1", func() (int, error) { return 2, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 2, value) // loaded synchronously
+}
+
+func TestWithRefreshAhead(t *testing.T) {
+	var calls atomic.Int32
+	loader := func(key string) (int, error) {
+		return int(calls.Add(1)) * 10, nil
+	}
+	cache := ugulru.NewInMemoryCache(2, 300*time.Millisecond, ugulru.WithRefreshAhead(100*time.Millisecond, loader))
+	cache.Put("key1", 1)
+
+	// Fresh entries are not refreshed
+	value, ok := cache.Get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, int32(0), calls.Load())
+
+	// A hit on an entry due for a refresh returns the current value and reloads it in the background
+	time.Sleep(150 * time.Millisecond)
+	value, ok = cache.Get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load())
+	value, ok = cache.Get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 10, value)
+
+	// The refreshed entry lives past the original TTL
+	time.Sleep(200 * time.Millisecond)
+	_, ok = cache.Get("key1")
+	assert.True(t, ok)
+}
+
+func TestWithSoftTTL(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(2, 300*time.Millisecond, ugulru.WithSoftTTL[string, int](100*time.Millisecond))
+	var calls atomic.Int32
+	loader := func() (int, error) {
+		return int(calls.Add(1)) * 10, nil
+	}
+
+	cache.Put("key1", 1)
+	time.Sleep(150 * time.Millisecond)
+
+	// Past the soft TTL the current value is served and refreshed in the background
+	value, err := cache.Load("key1", loader)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+	assert.NoError(t, cache.Flush(context.Background()))
+	value, err = cache.Load("key1", loader)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, value)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Past the hard TTL the value is loaded synchronously
+	time.Sleep(350 * time.Millisecond)
+	value, err = cache.Load("key1", loader)
+	assert.NoError(t, err)
+	assert.Equal(t, 20, value)
+}
+
+func TestInMemoryCache_PutWithTTL(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+	loader := func() (int, error) {
+		return 10, nil
+	}
+
+	cache.PutWithTTL("key1", 1, 50*time.Millisecond, 200*time.Millisecond)
+	_, expiry, ok := cache.GetWithExpiry("key1")
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(200*time.Millisecond), expiry, 20*time.Millisecond)
+
+	time.Sleep(100 * time.Millisecond)
+	value, err := cache.Load("key1", loader)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+	assert.NoError(t, cache.Flush(context.Background()))
+
+	// The refreshed entry gets the TTLs of the cache
+	value, err = cache.Load("key1", loader)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, value)
+	_, expiry, _ = cache.GetWithExpiry("key1")
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), expiry, 20*time.Millisecond)
+}
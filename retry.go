@@ -0,0 +1,50 @@
+package ugulru
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Retry wraps a loader so that failed calls are retried up to the given total number of attempts, with exponential
+// backoff starting at the given duration, growing up to one minute, and randomized by up to half of each delay to avoid
+// synchronized retries. Retrying stops early when the context is done, in which case the last loader error is
+// returned.
+func Retry[V any](loader func(ctx context.Context) (V, error), attempts int, backoff time.Duration) func(ctx context.Context) (V, error) {
+	return func(ctx context.Context) (V, error) {
+		var (
+			value V
+			err   error
+		)
+		for attempt := 1; ; attempt++ {
+			value, err = loader(ctx)
+			if err == nil || attempt >= attempts {
+				return value, err
+			}
+
+			timer := time.NewTimer(retryDelay(backoff, attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return value, err
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// maxRetryDelay is the delay at which the backoff of Retry stops growing, unless the initial backoff is longer.
+const maxRetryDelay = time.Minute
+
+// retryDelay returns the randomized delay before the given retry, counted from 1, with the given initial backoff.
+func retryDelay(backoff time.Duration, retry int) time.Duration {
+	if backoff <= 0 {
+		return 0
+	}
+	delay := backoff
+	for i := 1; i < retry && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, max(backoff, maxRetryDelay))
+	return delay - time.Duration(rand.Int64N(int64(delay/2)+1))
+}
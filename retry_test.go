@@ -0,0 +1,71 @@
+package ugulru_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry(t *testing.T) {
+	errTransient := errors.New("transient")
+
+	t.Run("Test succeeding after failures", func(t *testing.T) {
+		calls := 0
+		loader := ugulru.Retry(func(ctx context.Context) (int, error) {
+			calls++
+			if calls < 3 {
+				return 0, errTransient
+			}
+			return 1, nil
+		}, 3, time.Millisecond)
+
+		cache := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+		value, err := cache.LoadContext(context.Background(), "key1", loader)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, value)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("Test giving up", func(t *testing.T) {
+		calls := 0
+		loader := ugulru.Retry(func(ctx context.Context) (int, error) {
+			calls++
+			return 0, errTransient
+		}, 3, time.Millisecond)
+
+		_, err := loader(context.Background())
+		assert.ErrorIs(t, err, errTransient)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("Test context cancellation", func(t *testing.T) {
+		calls := 0
+		loader := ugulru.Retry(func(ctx context.Context) (int, error) {
+			calls++
+			return 0, errTransient
+		}, 10, time.Second)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err := loader(ctx)
+		assert.ErrorIs(t, err, errTransient)
+		assert.Equal(t, 1, calls)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	})
+}
+
+func TestRetryDelay(t *testing.T) {
+	for _, retry := range []int{1, 10, 63, 64, 1000, math.MaxInt} {
+		delay := ugulru.RetryDelay(time.Millisecond, retry)
+		assert.Positive(t, delay, retry)
+		assert.LessOrEqual(t, delay, time.Minute, retry)
+	}
+	assert.InDelta(t, time.Hour, ugulru.RetryDelay(time.Hour, 1000), float64(time.Hour/2))
+	assert.Zero(t, ugulru.RetryDelay(0, 10))
+}
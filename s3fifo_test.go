@@ -0,0 +1,82 @@
+package ugulru_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestS3FIFOCache(t *testing.T) {
+	cache := ugulru.NewS3FIFOCache[string, int](10, 100*time.Millisecond)
+
+	cache.Put("key1", 1)
+	value, ok := cache.Get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	cache.Put("key1", 2)
+	value, _ = cache.Get("key1")
+	assert.Equal(t, 2, value)
+
+	cache.Remove("key1")
+	_, ok = cache.Get("key1")
+	assert.False(t, ok)
+
+	value, err := cache.Load("key2", func() (int, error) { return 3, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 3, value)
+	_, err = cache.Load("key3", func() (int, error) { return 0, errors.New("loader error") })
+	assert.Error(t, err)
+
+	time.Sleep(150 * time.Millisecond)
+	_, ok = cache.Get("key2")
+	assert.False(t, ok)
+
+	cache.Put("key4", 4)
+	time.Sleep(150 * time.Millisecond)
+	cache.RemoveExpired()
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestS3FIFOCache_ScanResistance(t *testing.T) {
+	cache := ugulru.NewS3FIFOCache[string, int](10, 5*time.Minute)
+
+	for i := range 5 {
+		key := fmt.Sprintf("hot%d", i)
+		cache.Put(key, i)
+		cache.Get(key)
+	}
+	for i := range 100 {
+		cache.Put(fmt.Sprintf("scan%d", i), i)
+	}
+
+	assert.Equal(t, 10, cache.Len())
+	for i := range 5 {
+		_, ok := cache.Get(fmt.Sprintf("hot%d", i))
+		assert.True(t, ok, i)
+	}
+}
+
+func TestS3FIFOCache_Ghost(t *testing.T) {
+	cache := ugulru.NewS3FIFOCache[string, int](10, 5*time.Minute)
+
+	cache.Put("key", 1)
+	for i := range 9 {
+		cache.Put(fmt.Sprintf("filler%d", i), i)
+	}
+	cache.Put("other", 2) // evicts key from the small queue to the ghost queue
+	_, ok := cache.Get("key")
+	assert.False(t, ok)
+
+	// key comes back straight into the main queue and survives a scan
+	cache.Put("key", 1)
+	for i := range 100 {
+		cache.Put(fmt.Sprintf("scan%d", i), i)
+	}
+	_, ok = cache.Get("key")
+	assert.True(t, ok)
+}
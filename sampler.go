@@ -0,0 +1,97 @@
+package ugulru
+
+import (
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+)
+
+// sampleSize is the number of most recent samples the sampler keeps.
+const sampleSize = 1024
+
+// Percentiles holds the percentiles of a sampled duration.
+type Percentiles struct {
+	P50 time.Duration
+	P90 time.Duration
+	P99 time.Duration
+}
+
+// sampler records the latency and the lock wait time of a fraction of cache operations.
+type sampler struct {
+	rate     float64
+	mu       sync.Mutex
+	latency  []time.Duration
+	lockWait []time.Duration
+	next     int
+}
+
+// sample is an operation in progress that is being recorded by the sampler.
+type sample struct {
+	start time.Time
+	wait  time.Duration
+}
+
+// WithSampling enables the built-in sampling profiler, which records the latency and the lock wait time of the given
+// fraction of Get, Put, Remove and Load operations (e.g. 0.01 for 1%). The percentiles of the most recent samples are
+// reported by Stats.
+func WithSampling[K comparable, V any](rate float64) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.sampler = &sampler{rate: rate}
+	}
+}
+
+// lock acquires the lock of the cache. If the operation is sampled, the returned sample holds its start time and the
+// time spent waiting for the lock.
+func (c *InMemoryCache[K, V]) lock() sample {
+	if c.sampler == nil || rand.Float64() >= c.sampler.rate {
+		c.mu.Lock()
+		return sample{}
+	}
+	start := time.Now()
+	c.mu.Lock()
+	return sample{start: start, wait: time.Since(start)}
+}
+
+// unlock releases the lock of the cache and records the given sample, if any.
+func (c *InMemoryCache[K, V]) unlock(s sample) {
+	c.mu.Unlock()
+	if !s.start.IsZero() {
+		c.sampler.record(time.Since(s.start), s.wait)
+	}
+}
+
+func (s *sampler) record(latency, wait time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.latency) < sampleSize {
+		s.latency = append(s.latency, latency)
+		s.lockWait = append(s.lockWait, wait)
+		return
+	}
+	s.latency[s.next] = latency
+	s.lockWait[s.next] = wait
+	s.next = (s.next + 1) % sampleSize
+}
+
+// percentiles returns the percentiles of the recorded latencies and lock wait times.
+func (s *sampler) percentiles() (latency, lockWait Percentiles) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return percentilesOf(s.latency), percentilesOf(s.lockWait)
+}
+
+func percentilesOf(samples []time.Duration) Percentiles {
+	if len(samples) == 0 {
+		return Percentiles{}
+	}
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+	at := func(p int) time.Duration {
+		// Nearest-rank method
+		return sorted[(len(sorted)*p+99)/100-1]
+	}
+	return Percentiles{P50: at(50), P90: at(90), P99: at(99)}
+}
@@ -0,0 +1,159 @@
+// Package sim replays access traces against the caches of ugulru with different eviction policies and capacities, to
+// guide tuning before deployment.
+package sim
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/machine23/ugulru"
+)
+
+// Access is a single access of a trace.
+type Access struct {
+	Key string
+	// Time is the time of the access, or the zero time if the trace has no timestamps.
+	Time time.Time
+}
+
+// ReadTrace reads a trace with one access per line: either a bare key, or a key and a timestamp separated by a comma.
+// Timestamps are in RFC 3339 format or Unix seconds. Empty lines are skipped.
+func ReadTrace(r io.Reader) ([]Access, error) {
+	var trace []Access
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		key, ts, found := strings.Cut(text, ",")
+		access := Access{Key: key}
+		if found {
+			t, err := parseTime(strings.TrimSpace(ts))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			access.Time = t
+		}
+		trace = append(trace, access)
+	}
+	return trace, scanner.Err()
+}
+
+func parseTime(s string) (time.Time, error) {
+	var sec int64
+	if _, err := fmt.Sscanf(s, "%d", &sec); err == nil && !strings.ContainsAny(s, "-:T") {
+		return time.Unix(sec, 0), nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+// Policies are the names of the eviction policies that can be simulated.
+var Policies = []string{"lru", "lfu", "slru", "tinylfu", "s3fifo", "clock"}
+
+// Result is the outcome of replaying a trace against one policy and capacity.
+type Result struct {
+	Policy    string
+	Capacity  int
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	// KeyMemory is the approximate number of bytes used by the keys resident at the end of the replay.
+	KeyMemory int64
+}
+
+// HitRatio returns the fraction of accesses that were hits, between 0 and 1.
+func (r Result) HitRatio() float64 {
+	if total := r.Hits + r.Misses; total > 0 {
+		return float64(r.Hits) / float64(total)
+	}
+	return 0
+}
+
+// cache is the part of a cache the simulation needs.
+type cache interface {
+	Get(key string) (struct{}, bool)
+	Put(key string, value struct{})
+	Len() int
+}
+
+type inMemory struct {
+	*ugulru.InMemoryCache[string, struct{}]
+}
+
+func (c inMemory) Len() int {
+	return c.Stats().Len
+}
+
+func newCache(policy string, capacity int) (cache, error) {
+	// Long enough for entries to never expire during a replay
+	const ttl = 100 * 365 * 24 * time.Hour
+	switch policy {
+	case "lru":
+		return inMemory{ugulru.NewInMemoryCache[string, struct{}](capacity, ttl)}, nil
+	case "lfu":
+		return inMemory{ugulru.NewInMemoryCache(capacity, ttl, ugulru.WithLFUEviction[string, struct{}]())}, nil
+	case "slru":
+		return inMemory{ugulru.NewInMemoryCache(capacity, ttl, ugulru.WithSegmentedLRU[string, struct{}](0.8))}, nil
+	case "tinylfu":
+		return inMemory{ugulru.NewInMemoryCache(capacity, ttl, ugulru.WithTinyLFU[string, struct{}]())}, nil
+	case "s3fifo":
+		return ugulru.NewS3FIFOCache[string, struct{}](capacity, ttl), nil
+	case "clock":
+		return ugulru.NewClockCache[string, struct{}](capacity, ttl), nil
+	}
+	return nil, fmt.Errorf("unknown policy %q, want one of %s", policy, strings.Join(Policies, ", "))
+}
+
+// Replay replays the trace against a cache with the given policy and capacity. Every miss is followed by a write of
+// the key, as a loading cache would do. Entries never expire.
+func Replay(trace []Access, policy string, capacity int) (Result, error) {
+	c, err := newCache(policy, capacity)
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{Policy: policy, Capacity: capacity}
+	keyBytes := make(map[string]int)
+	for _, access := range trace {
+		if _, ok := c.Get(access.Key); ok {
+			result.Hits++
+			continue
+		}
+		result.Misses++
+		c.Put(access.Key, struct{}{})
+		keyBytes[access.Key] = len(access.Key)
+	}
+	resident := c.Len()
+	result.Evictions = result.Misses - uint64(resident)
+	if len(keyBytes) > 0 {
+		var total int
+		for _, n := range keyBytes {
+			total += n
+		}
+		// Each key is a string header plus its bytes, assuming the resident keys have the average length
+		avg := float64(total) / float64(len(keyBytes))
+		result.KeyMemory = int64(float64(resident) * (16 + avg))
+	}
+	return result, nil
+}
+
+// ReplayAll replays the trace against every combination of the given policies and capacities, returning the results
+// ordered by policy and capacity.
+func ReplayAll(trace []Access, policies []string, capacities []int) ([]Result, error) {
+	capacities = slices.Sorted(slices.Values(capacities))
+	var results []Result
+	for _, policy := range policies {
+		for _, capacity := range capacities {
+			result, err := Replay(trace, policy, capacity)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,51 @@
+package sim_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru/sim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadTrace(t *testing.T) {
+	trace, err := sim.ReadTrace(strings.NewReader("a\n\nb,1700000000\nc, 2024-01-02T03:04:05Z\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []sim.Access{
+		{Key: "a"},
+		{Key: "b", Time: time.Unix(1700000000, 0)},
+		{Key: "c", Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}, trace)
+
+	_, err = sim.ReadTrace(strings.NewReader("a,yesterday\n"))
+	assert.ErrorContains(t, err, "line 1")
+}
+
+func TestReplay(t *testing.T) {
+	var trace []sim.Access
+	for range 3 {
+		for i := range 4 {
+			trace = append(trace, sim.Access{Key: fmt.Sprintf("key%d", i)})
+		}
+	}
+
+	result, err := sim.Replay(trace, "lru", 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), result.Hits)
+	assert.Equal(t, uint64(12), result.Misses)
+	assert.Equal(t, uint64(10), result.Evictions)
+	assert.Equal(t, int64(2*(16+4)), result.KeyMemory)
+
+	results, err := sim.ReplayAll(trace, sim.Policies, []int{8, 4})
+	require.NoError(t, err)
+	assert.Len(t, results, 2*len(sim.Policies))
+	for _, result := range results {
+		assert.Equal(t, 8.0/12, result.HitRatio(), "%s/%d", result.Policy, result.Capacity)
+	}
+
+	_, err = sim.Replay(trace, "fifo", 2)
+	assert.Error(t, err)
+}
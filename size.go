@@ -0,0 +1,149 @@
+package ugulru
+
+import (
+	"math"
+	"reflect"
+	"time"
+	"unsafe"
+)
+
+// NewInMemoryCacheWithMaxBytes creates a new in-memory cache that holds at most approximately maxBytes bytes of keys,
+// values and bookkeeping, rather than a fixed number of entries. The size of an entry is estimated as described in
+// EstimateSize. The number of entries is unbounded, so WithTinyLFU and WithGhostCaches, which are sized by the
+// capacity, panic in this mode, and WithSegmentedLRU protects a fraction of the entries held rather than of the
+// capacity.
+func NewInMemoryCacheWithMaxBytes[K comparable, V any](maxBytes int64, ttl time.Duration, opts ...Option[K, V]) *InMemoryCache[K, V] {
+	opts = append([]Option[K, V]{WithCost[K, V](nil, maxBytes)}, opts...)
+	return NewInMemoryCache(math.MaxInt, ttl, opts...)
+}
+
+// unbounded reports whether the number of entries is unbounded, as with NewInMemoryCacheWithMaxBytes.
+func (c *InMemoryCache[K, V]) unbounded() bool {
+	return c.capacity == math.MaxInt
+}
+
+// Sizer is implemented by values that know their own size in bytes, such as generated protobuf messages. EstimateSize
+// uses it instead of walking the value.
+type Sizer interface {
+	Size() int
+}
+
+// EstimateSize returns the approximate number of bytes used by the given value, including the memory it references
+// through strings, slices, maps, pointers and interfaces. Memory shared by several references is counted once. Strings
+// and []byte are counted by their length and capacity, and values implementing Sizer by their reported size, without
+// walking them. The estimate doesn't include allocator and map overhead, and counts nothing for channels and
+// functions.
+func EstimateSize(v any) int64 {
+	if v == nil {
+		return 0
+	}
+	rv := reflect.ValueOf(v)
+	return int64(rv.Type().Size()) + newSizer().referenced(rv)
+}
+
+// entrySize returns the approximate number of bytes used by an entry with the given key and value.
+func entrySize[K comparable, V any](key K, value V) int64 {
+	var e entry[K, V]
+	// The map holds a copy of the key and a pointer to the entry
+	size := unsafe.Sizeof(e) + unsafe.Sizeof(key) + unsafe.Sizeof(&e)
+	s := newSizer()
+	return int64(size) + s.referenced(reflect.ValueOf(&key).Elem()) + s.referenced(reflect.ValueOf(&value).Elem())
+}
+
+// sizer estimates the memory referenced by values, remembering the visited pointers to count shared memory once.
+type sizer struct {
+	visited map[uintptr]struct{}
+}
+
+func newSizer() *sizer {
+	return &sizer{visited: make(map[uintptr]struct{})}
+}
+
+// visit reports whether the memory at the given address has not been counted yet, and marks it as counted.
+func (s *sizer) visit(ptr uintptr) bool {
+	if _, ok := s.visited[ptr]; ok {
+		return false
+	}
+	s.visited[ptr] = struct{}{}
+	return true
+}
+
+// referenced returns the number of bytes referenced by the given value, excluding the value itself.
+func (s *sizer) referenced(v reflect.Value) int64 {
+	if v.CanInterface() {
+		if sizer, ok := v.Interface().(Sizer); ok && (v.Kind() != reflect.Pointer || !v.IsNil()) {
+			return int64(sizer.Size())
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Len())
+	case reflect.Slice:
+		if v.IsNil() || !s.visit(v.Pointer()) {
+			return 0
+		}
+		size := int64(v.Cap()) * int64(v.Type().Elem().Size())
+		if hasPointers(v.Type().Elem()) {
+			for i := range v.Len() {
+				size += s.referenced(v.Index(i))
+			}
+		}
+		return size
+	case reflect.Map:
+		if v.IsNil() || !s.visit(v.Pointer()) {
+			return 0
+		}
+		size := int64(v.Len()) * int64(v.Type().Key().Size()+v.Type().Elem().Size())
+		if hasPointers(v.Type().Key()) || hasPointers(v.Type().Elem()) {
+			for iter := v.MapRange(); iter.Next(); {
+				size += s.referenced(iter.Key()) + s.referenced(iter.Value())
+			}
+		}
+		return size
+	case reflect.Pointer:
+		if v.IsNil() || !s.visit(v.Pointer()) {
+			return 0
+		}
+		return int64(v.Type().Elem().Size()) + s.referenced(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		elem := v.Elem()
+		return int64(elem.Type().Size()) + s.referenced(elem)
+	case reflect.Struct:
+		var size int64
+		for i := range v.NumField() {
+			size += s.referenced(v.Field(i))
+		}
+		return size
+	case reflect.Array:
+		var size int64
+		if hasPointers(v.Type().Elem()) {
+			for i := range v.Len() {
+				size += s.referenced(v.Index(i))
+			}
+		}
+		return size
+	}
+	return 0
+}
+
+// hasPointers reports whether values of the given type may reference other memory.
+func hasPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Pointer, reflect.Interface, reflect.Chan, reflect.Func,
+		reflect.UnsafePointer:
+		return true
+	case reflect.Array:
+		return hasPointers(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if hasPointers(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package ugulru_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewInMemoryCacheWithMaxBytes(t *testing.T) {
+	cache := ugulru.NewInMemoryCacheWithMaxBytes[string, []byte](4096, 5*time.Minute)
+
+	cache.Put("key1", make([]byte, 1024))
+	cache.Put("key2", make([]byte, 1024))
+	cache.Put("key3", make([]byte, 1024))
+	assert.Equal(t, 3, cache.Stats().Len)
+
+	usage := cache.PutWithUsage("key4", make([]byte, 1024))
+	assert.Greater(t, usage.Cost, int64(1024))
+	_, ok := cache.Get("key1")
+	assert.False(t, ok)
+	assert.LessOrEqual(t, cache.Utilization(), 1.0)
+
+	// Many small entries fit
+	for i := range 20 {
+		cache.Put(string(rune('a'+i)), []byte{1})
+	}
+	assert.Greater(t, cache.Stats().Len, 4)
+}
+
+func TestNewInMemoryCacheWithMaxBytes_Policies(t *testing.T) {
+	assert.Panics(t, func() {
+		ugulru.NewInMemoryCacheWithMaxBytes(4096, time.Minute, ugulru.WithTinyLFU[string, int]())
+	})
+	assert.Panics(t, func() {
+		ugulru.NewInMemoryCacheWithMaxBytes(4096, time.Minute, ugulru.WithGhostCaches[string, int](2))
+	})
+
+	// The protected segment holds half of the entries
+	cache := ugulru.NewInMemoryCacheWithMaxBytes(1<<20, time.Minute, ugulru.WithSegmentedLRU[int, int](0.5))
+	for i := range 10 {
+		cache.Put(i, i)
+	}
+	for i := range 10 {
+		cache.Get(i)
+	}
+	assert.Equal(t, 5, cache.ProtectedLen())
+}
+
+type sizedMessage struct{ payload []byte }
+
+func (m *sizedMessage) Size() int { return 100 }
+
+func TestEstimateSize(t *testing.T) {
+	type node struct {
+		name string
+		next *node
+	}
+
+	assert.Equal(t, int64(0), ugulru.EstimateSize(nil))
+	assert.Equal(t, int64(8), ugulru.EstimateSize(int64(1)))
+	assert.Equal(t, int64(16+5), ugulru.EstimateSize("hello"))
+	assert.Equal(t, int64(24+64), ugulru.EstimateSize(make([]byte, 10, 64)))
+	assert.Equal(t, int64(24+2*16+3+3), ugulru.EstimateSize([]string{"foo", "bar"}))
+	assert.Equal(t, int64(8+100), ugulru.EstimateSize(&sizedMessage{payload: make([]byte, 1024)}))
+
+	// Shared and cyclic references are counted once
+	a := &node{name: "a"}
+	b := &node{name: "bb", next: a}
+	a.next = b
+	assert.Equal(t, int64(8+24+1+24+2), ugulru.EstimateSize(a))
+	assert.Equal(t, int64(24+2*8+24+1), ugulru.EstimateSize([]*node{{name: "c"}, nil}))
+}
+
+func TestWithCost_EstimatedSize(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(100, 5*time.Minute, ugulru.WithCost[int, map[string]string](nil, 2048))
+
+	small := cache.PutWithUsage(1, map[string]string{"a": "b"})
+	large := cache.PutWithUsage(2, map[string]string{"a": string(make([]byte, 1024))})
+	assert.Greater(t, large.Cost, small.Cost+1000)
+
+	cache.Put(3, map[string]string{"a": string(make([]byte, 1024))})
+	_, ok := cache.Get(1)
+	assert.False(t, ok)
+}
@@ -0,0 +1,56 @@
+package ugulru
+
+// WithSegmentedLRU splits the cache into a probationary and a protected segment. New entries enter the probationary
+// segment and are promoted to the protected one on their first hit; the protected segment holds up to the given
+// fraction of the capacity (e.g. 0.8), and its least recently used entries are demoted back to the probationary
+// segment when it overflows. Eviction removes the least recently used probationary entry, so bulk scans of keys that
+// are read only once can't evict the established working set. If the capacity is unbounded, as with
+// NewInMemoryCacheWithMaxBytes, the protected segment holds up to the given fraction of the entries instead.
+func WithSegmentedLRU[K comparable, V any](protected float64) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.protectedShare = protected
+	}
+}
+
+// promote moves the given live entry to the protected segment, demoting the least recently used protected entry if
+// the segment overflows. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) promote(promoted *entry[K, V]) {
+	if promoted.protected {
+		return
+	}
+	promoted.protected = true
+	c.protectedLen++
+	if c.protectedLen <= c.protectedCap() {
+		return
+	}
+	for e := c.list.Back(); e != nil; e = e.Prev() {
+		if e.protected {
+			e.protected = false
+			c.protectedLen--
+			c.list.MoveToFront(e)
+			e.moved = c.nextMove()
+			return
+		}
+	}
+}
+
+// protectedCap returns the capacity of the protected segment. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) protectedCap() int {
+	size := c.capacity
+	if c.unbounded() {
+		size = c.list.Len()
+	}
+	return max(int(float64(size)*c.protectedShare), 1)
+}
+
+// probationVictim returns the least recently used unpinned entry of the probationary segment, or of the protected
+// segment if the probationary one is empty, or nil if all the entries are pinned. It must be called with the lock
+// held.
+func (c *InMemoryCache[K, V]) probationVictim() *entry[K, V] {
+	for e := c.evictable(c.list.Back()); e != nil; e = c.evictable(e.Prev()) {
+		if !e.protected {
+			return e
+		}
+	}
+	return c.evictable(c.list.Back())
+}
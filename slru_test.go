@@ -0,0 +1,64 @@
+package ugulru_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSegmentedLRU(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(4, 5*time.Minute, ugulru.WithSegmentedLRU[string, int](0.5))
+
+	cache.Put("hot1", 1)
+	cache.Put("hot2", 2)
+	cache.Get("hot1")
+	cache.Get("hot2")
+
+	// A scan only evicts probationary entries
+	for i := range 10 {
+		cache.Put(fmt.Sprintf("scan%d", i), i)
+	}
+	for _, key := range []string{"hot1", "hot2", "scan8", "scan9"} {
+		_, ok := cache.Get(key)
+		assert.True(t, ok, key)
+	}
+
+	// scan9 and scan8 are promoted by the reads above, demoting hot1 and hot2, which are evicted next
+	cache.Put("new1", 1)
+	cache.Put("new2", 2)
+	_, ok := cache.Get("hot1")
+	assert.False(t, ok)
+	_, ok = cache.Get("hot2")
+	assert.False(t, ok)
+}
+
+func TestWithSegmentedLRURewrite(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(10, 5*time.Minute, ugulru.WithSegmentedLRU[string, int](0.5))
+	for i := range 5 {
+		key := fmt.Sprint(i)
+		cache.Put(key, i)
+		cache.Get(key)
+	}
+	assert.Equal(t, 5, cache.ProtectedLen())
+
+	// Rewritten entries go back to the probationary segment
+	for i := range 5 {
+		cache.Put(fmt.Sprint(i), i+1)
+	}
+	assert.Equal(t, 0, cache.ProtectedLen())
+	for i := range 5 {
+		cache.Remove(fmt.Sprint(i))
+	}
+	assert.Equal(t, 0, cache.ProtectedLen())
+
+	// The protected segment still demotes its entries when it overflows
+	for i := range 8 {
+		key := fmt.Sprint(i)
+		cache.Put(key, i)
+		cache.Get(key)
+	}
+	assert.Equal(t, 5, cache.ProtectedLen())
+}
@@ -0,0 +1,191 @@
+package ugulru
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// Versions of the snapshot format. Snapshots of caches using a Codec hold encoded values.
+const (
+	snapshotVersion      = 1
+	snapshotVersionCodec = 2
+)
+
+// snapshotHeader starts a snapshot and is followed by Len entries.
+type snapshotHeader struct {
+	Version int
+	Len     int
+}
+
+// snapshotEntry is a single entry of a snapshot.
+type snapshotEntry[K comparable, V any] struct {
+	Key     K
+	Value   V
+	Expires time.Time
+}
+
+// encodedEntry is a single entry of a snapshot written with a Codec.
+type encodedEntry[K comparable] struct {
+	Key     K
+	Value   []byte
+	Expires time.Time
+}
+
+// Snapshot writes the live entries of the cache to w with encoding/gob, from the most to the least recently used,
+// together with the time each entry expires, so the cache can be persisted on shutdown and reloaded with Restore. The
+// key type must be encodable with gob, and so must the value type unless the values are encoded with the codec set by
+// WithCodec. Negative entries are not written.
+func (c *InMemoryCache[K, V]) Snapshot(w io.Writer) error {
+	if c.aead != nil {
+		var buf bytes.Buffer
+		if err := c.writeSnapshot(&buf); err != nil {
+			return err
+		}
+		return c.seal(w, buf.Bytes())
+	}
+	return c.writeSnapshot(w)
+}
+
+// writeSnapshot writes an unencrypted snapshot of the cache to w.
+func (c *InMemoryCache[K, V]) writeSnapshot(w io.Writer) error {
+	entries := c.snapshot()
+
+	version := snapshotVersion
+	if c.codec != nil {
+		version = snapshotVersionCodec
+	}
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(snapshotHeader{Version: version, Len: len(entries)}); err != nil {
+		return fmt.Errorf("ugulru: write snapshot: %w", err)
+	}
+	for _, e := range entries {
+		if err := c.writeEntry(enc, e); err != nil {
+			return fmt.Errorf("ugulru: write snapshot: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeEntry writes the given snapshot entry, encoding the value with the codec of the cache if any.
+func (c *InMemoryCache[K, V]) writeEntry(enc *gob.Encoder, e snapshotEntry[K, V]) error {
+	if c.codec == nil {
+		return enc.Encode(e)
+	}
+	value, err := c.codec.Encode(e.Value)
+	if err != nil {
+		return err
+	}
+	return enc.Encode(encodedEntry[K]{Key: e.Key, Value: value, Expires: e.Expires})
+}
+
+// readEntry reads a snapshot entry written with the given version of the snapshot format.
+func (c *InMemoryCache[K, V]) readEntry(dec *gob.Decoder, version int) (snapshotEntry[K, V], error) {
+	var e snapshotEntry[K, V]
+	if version == snapshotVersion {
+		err := dec.Decode(&e)
+		return e, err
+	}
+	var encoded encodedEntry[K]
+	if err := dec.Decode(&encoded); err != nil {
+		return e, err
+	}
+	value, err := c.codec.Decode(encoded.Value)
+	if err != nil {
+		return e, err
+	}
+	return snapshotEntry[K, V]{Key: encoded.Key, Value: value, Expires: encoded.Expires}, nil
+}
+
+// snapshot returns the live entries of the cache from the most to the least recently used.
+func (c *InMemoryCache[K, V]) snapshot() []snapshotEntry[K, V] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := make([]snapshotEntry[K, V], 0, c.list.Len())
+	for entry := c.list.Front(); entry != nil; entry = entry.Next() {
+		if c.expired(entry) || entry.err != nil {
+			continue
+		}
+		entries = append(entries, snapshotEntry[K, V]{
+			Key:     entry.key,
+			Value:   entry.value,
+			Expires: c.timeOf(c.expiresAt(entry)),
+		})
+	}
+	return entries
+}
+
+// Restore reads a snapshot written by Snapshot from r and writes its entries to the cache with the time they expire,
+// skipping the ones that have expired since, so a service can come back warm after a restart. If the snapshot holds
+// more entries than the capacity of the cache, only the most recently used ones are restored. Entries already in the
+// cache are kept unless they are overwritten or evicted.
+func (c *InMemoryCache[K, V]) Restore(r io.Reader) error {
+	c.mu.Lock()
+	capacity := c.capacity
+	c.mu.Unlock()
+
+	if c.aead != nil {
+		plaintext, err := c.open(r)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(plaintext)
+	}
+	dec := gob.NewDecoder(r)
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return fmt.Errorf("ugulru: read snapshot: %w", err)
+	}
+	switch {
+	case header.Version == snapshotVersionCodec && c.codec == nil:
+		return errors.New("ugulru: snapshot values are encoded with a codec, use WithCodec to restore it")
+	case header.Version != snapshotVersion && header.Version != snapshotVersionCodec:
+		return fmt.Errorf("ugulru: unsupported snapshot version %d", header.Version)
+	case header.Len < 0:
+		return fmt.Errorf("ugulru: invalid snapshot length %d", header.Len)
+	}
+	now := time.Now()
+	// The length comes from the snapshot and may be corrupt, so the entries are appended as they are read
+	var entries []snapshotEntry[K, V]
+	for range header.Len {
+		if len(entries) == capacity {
+			break
+		}
+		e, err := c.readEntry(dec, header.Version)
+		if err != nil {
+			return fmt.Errorf("ugulru: read snapshot: %w", err)
+		}
+		if e.Expires.After(now) {
+			entries = append(entries, e)
+		}
+	}
+
+	c.restoreAll(entries)
+	return nil
+}
+
+// restoreAll writes the given snapshot entries to the cache, ordered from the most to the least recently used.
+func (c *InMemoryCache[K, V]) restoreAll(entries []snapshotEntry[K, V]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Write the least recently used entries first, so the order of the cache matches the snapshot
+	for _, e := range slices.Backward(entries) {
+		c.restore(e)
+	}
+}
+
+// restore writes the given snapshot entry to the cache. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) restore(e snapshotEntry[K, V]) {
+	c.store(e.Key, e.Value, func(entry *entry[K, V]) {
+		entry.ttl = time.Until(e.Expires)
+		if entry.ttl <= 0 {
+			entry.ttl = -1
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package ugulru_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryCache_Snapshot(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+	cache.PutWithTTL("expired", 3, 0, time.Nanosecond)
+	time.Sleep(2 * time.Millisecond)
+
+	var buf bytes.Buffer
+	require.NoError(t, cache.Snapshot(&buf))
+
+	var header struct{ Version, Len int }
+	dec := gob.NewDecoder(&buf)
+	require.NoError(t, dec.Decode(&header))
+	assert.Equal(t, 2, header.Len)
+
+	var entry struct {
+		Key     string
+		Value   int
+		Expires time.Time
+	}
+	require.NoError(t, dec.Decode(&entry))
+	assert.Equal(t, "key2", entry.Key)
+	assert.Equal(t, 2, entry.Value)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), entry.Expires, time.Second)
+	require.NoError(t, dec.Decode(&entry))
+	assert.Equal(t, "key1", entry.Key)
+}
+
+func TestInMemoryCache_Restore(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+	cache.Put("key1", 1)
+	cache.PutWithTTL("short", 2, 0, 50*time.Millisecond)
+	cache.Put("key3", 3)
+
+	var buf bytes.Buffer
+	require.NoError(t, cache.Snapshot(&buf))
+	time.Sleep(100 * time.Millisecond)
+
+	restored := ugulru.NewInMemoryCache[string, int](3, time.Hour)
+	require.NoError(t, restored.Restore(bytes.NewReader(buf.Bytes())))
+	assert.Equal(t, []string{"key3", "key1"}, slices.Collect(restored.Keys()))
+
+	_, expiry, ok := restored.GetWithExpiry("key1")
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), expiry, time.Second)
+
+	// Only the most recently used entries fit a smaller cache
+	small := ugulru.NewInMemoryCache[string, int](1, time.Hour)
+	require.NoError(t, small.Restore(bytes.NewReader(buf.Bytes())))
+	assert.Equal(t, []string{"key3"}, slices.Collect(small.Keys()))
+
+	assert.Error(t, small.Restore(bytes.NewReader([]byte("garbage"))))
+}
+
+func TestInMemoryCache_RestoreCorruptLength(t *testing.T) {
+	header := func(length int) []byte {
+		var buf bytes.Buffer
+		require.NoError(t, gob.NewEncoder(&buf).Encode(struct{ Version, Len int }{1, length}))
+		return buf.Bytes()
+	}
+
+	cache := ugulru.NewInMemoryCacheWithMaxBytes[string, int](1<<20, time.Hour)
+	assert.Error(t, cache.Restore(bytes.NewReader(header(-1))))
+	assert.Error(t, cache.Restore(bytes.NewReader(header(math.MaxInt))), "the missing entries should fail to read")
+	assert.Empty(t, slices.Collect(cache.Keys()))
+}
@@ -0,0 +1,63 @@
+package ugulru
+
+// SplitCache is a Cache that keeps positive results and negative results, such as "not found" markers, in two separate
+// caches with their own capacities and TTLs, so lookups of junk keys can't evict real data.
+type SplitCache[K comparable, V any] struct {
+	positive   Cache[K, V]
+	negative   Cache[K, error]
+	isNegative func(err error) bool
+}
+
+var _ Cache[string, any] = (*SplitCache[string, any])(nil)
+
+// NewSplitCache creates a new split cache storing values in positive and the loader errors recognized by isNegative in
+// negative.
+func NewSplitCache[K comparable, V any](positive Cache[K, V], negative Cache[K, error], isNegative func(err error) bool) *SplitCache[K, V] {
+	return &SplitCache[K, V]{positive: positive, negative: negative, isNegative: isNegative}
+}
+
+// Get retrieves a value from the positive cache. Keys with a cached negative result are reported as missing.
+func (s *SplitCache[K, V]) Get(key K) (V, bool) {
+	return s.positive.Get(key)
+}
+
+// Put stores the value in the positive cache and drops any negative result cached for the key.
+func (s *SplitCache[K, V]) Put(key K, value V) {
+	s.negative.Remove(key)
+	s.positive.Put(key, value)
+}
+
+// Remove deletes the key from both caches.
+func (s *SplitCache[K, V]) Remove(key K) {
+	s.positive.Remove(key)
+	s.negative.Remove(key)
+}
+
+// RemoveExpired removes all expired entries from both caches.
+func (s *SplitCache[K, V]) RemoveExpired() {
+	s.positive.RemoveExpired()
+	s.negative.RemoveExpired()
+}
+
+// Load retrieves the value from the positive cache or the cached error from the negative cache. If neither cache has
+// the key, the loader is called: values are stored in the positive cache, errors recognized as negative results in the
+// negative cache, and other errors are not cached.
+func (s *SplitCache[K, V]) Load(key K, loader func() (V, error)) (V, error) {
+	if value, ok := s.positive.Get(key); ok {
+		return value, nil
+	}
+	if err, ok := s.negative.Get(key); ok {
+		var zero V
+		return zero, err
+	}
+
+	value, err := loader()
+	if err != nil {
+		if s.isNegative(err) {
+			s.negative.Put(key, err)
+		}
+		return value, err
+	}
+	s.Put(key, value)
+	return value, nil
+}
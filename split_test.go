@@ -0,0 +1,64 @@
+package ugulru_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitCache(t *testing.T) {
+	errNotFound := errors.New("not found")
+	positive := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+	negative := ugulru.NewInMemoryCache[string, error](2, time.Minute)
+	cache := ugulru.NewSplitCache[string, int](positive, negative, func(err error) bool {
+		return errors.Is(err, errNotFound)
+	})
+
+	calls := 0
+	loader := func(value int, err error) func() (int, error) {
+		return func() (int, error) {
+			calls++
+			return value, err
+		}
+	}
+
+	value, err := cache.Load("key1", loader(1, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+
+	// Many negative results don't evict positive ones
+	for _, key := range []string{"junk1", "junk2", "junk3"} {
+		_, err = cache.Load(key, loader(0, errNotFound))
+		assert.ErrorIs(t, err, errNotFound)
+	}
+	value, ok := cache.Get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	// Negative results are cached
+	_, err = cache.Load("junk3", loader(0, errNotFound))
+	assert.ErrorIs(t, err, errNotFound)
+	assert.Equal(t, 4, calls)
+	_, ok = cache.Get("junk3")
+	assert.False(t, ok)
+
+	// Other errors are not cached
+	_, err = cache.Load("key2", loader(0, errors.New("unavailable")))
+	assert.Error(t, err)
+	_, ok = negative.Get("key2")
+	assert.False(t, ok)
+
+	// Put replaces a negative result
+	cache.Put("junk3", 3)
+	value, err = cache.Load("junk3", loader(0, errNotFound))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, value)
+
+	cache.Remove("junk3")
+	_, ok = cache.Get("junk3")
+	assert.False(t, ok)
+	cache.RemoveExpired()
+}
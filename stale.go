@@ -0,0 +1,53 @@
+package ugulru
+
+import "time"
+
+// StaleError is returned by Load together with a stale value when the loader failed and the stale value was served
+// instead, if flagging is enabled with WithStaleIfError.
+type StaleError struct {
+	// Err is the error returned by the loader.
+	Err error
+}
+
+func (e *StaleError) Error() string {
+	return "ugulru: serving stale value: " + e.Err.Error()
+}
+
+func (e *StaleError) Unwrap() error {
+	return e.Err
+}
+
+// WithStaleIfError keeps expired entries for the given window after they expire. When the loader called by Load fails
+// and such a stale entry exists, Load returns the stale value instead of the error, so transient backend outages don't
+// take down read paths. Errors recognized as negative results by WithNegativeCaching are always returned.
+//
+// If flag is true, Load returns a *StaleError wrapping the loader error together with the stale value, so callers can
+// tell that the value is stale; otherwise the error is nil.
+func WithStaleIfError[K comparable, V any](window time.Duration, flag bool) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.staleIfError = window
+		c.flagStale = flag
+	}
+}
+
+// staleOnError returns the stale value to serve for the given key when the loader fails, if any. It must be called
+// with the lock held.
+func (c *InMemoryCache[K, V]) staleOnError(key K) (V, bool) {
+	var zero V
+	entry, ok := c.cache[key]
+	if !ok {
+		return zero, false
+	}
+	if entry.err != nil || !c.withinStale(entry, c.staleIfError) {
+		return zero, false
+	}
+	return entry.value, true
+}
+
+// staleError returns the error to return together with a stale value served because of the given loader error.
+func (c *InMemoryCache[K, V]) staleError(err error) error {
+	if !c.flagStale {
+		return nil
+	}
+	return &StaleError{Err: err}
+}
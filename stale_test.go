@@ -0,0 +1,54 @@
+package ugulru_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithStaleIfError(t *testing.T) {
+	errUnavailable := errors.New("unavailable")
+	failing := func() (int, error) { return 0, errUnavailable }
+
+	t.Run("Test serving the stale value", func(t *testing.T) {
+		cache := ugulru.NewInMemoryCache(2, 50*time.Millisecond, ugulru.WithStaleIfError[string, int](time.Second, false))
+		cache.Put("key1", 1)
+		time.Sleep(100 * time.Millisecond)
+
+		_, ok := cache.Get("key1")
+		assert.False(t, ok)
+
+		value, err := cache.Load("key1", failing)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, value)
+
+		// A successful load replaces the stale value
+		value, err = cache.Load("key1", func() (int, error) { return 2, nil })
+		assert.NoError(t, err)
+		assert.Equal(t, 2, value)
+	})
+
+	t.Run("Test flagging the stale value", func(t *testing.T) {
+		cache := ugulru.NewInMemoryCache(2, 50*time.Millisecond, ugulru.WithStaleIfError[string, int](time.Second, true))
+		cache.Put("key1", 1)
+		time.Sleep(100 * time.Millisecond)
+
+		value, err := cache.Load("key1", failing)
+		var staleErr *ugulru.StaleError
+		assert.ErrorAs(t, err, &staleErr)
+		assert.ErrorIs(t, err, errUnavailable)
+		assert.Equal(t, 1, value)
+	})
+
+	t.Run("Test outside the window", func(t *testing.T) {
+		cache := ugulru.NewInMemoryCache(2, 50*time.Millisecond, ugulru.WithStaleIfError[string, int](50*time.Millisecond, false))
+		cache.Put("key1", 1)
+		time.Sleep(150 * time.Millisecond)
+
+		_, err := cache.Load("key1", failing)
+		assert.ErrorIs(t, err, errUnavailable)
+	})
+}
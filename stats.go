@@ -0,0 +1,154 @@
+package ugulru
+
+import "strings"
+
+// Stats holds the usage statistics of a cache.
+type Stats struct {
+	// Hits is the number of lookups that found a live entry.
+	Hits uint64
+	// Misses is the number of lookups that found no entry or an expired one.
+	Misses uint64
+	// Evictions is the number of entries evicted to make room for new ones.
+	Evictions uint64
+	// Len is the number of entries in the cache, including the expired ones that have not been removed yet.
+	Len int
+	// Capacity is the maximum number of entries in the cache.
+	Capacity int
+	// Latency holds the percentiles of the sampled operation latencies, including the lock wait time. It is only
+	// reported when sampling is enabled with WithSampling.
+	Latency Percentiles
+	// LockWait holds the percentiles of the time sampled operations spent waiting for the lock. It is only reported
+	// when sampling is enabled with WithSampling.
+	LockWait Percentiles
+}
+
+// HitRatio returns the ratio of hits to all lookups, or 0 if there were no lookups.
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// Stats returns the usage statistics of the cache.
+func (c *InMemoryCache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	stats := Stats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions,
+		Len:       c.list.Len(),
+		Capacity:  c.capacity,
+	}
+	c.mu.Unlock()
+
+	if c.sampler != nil {
+		stats.Latency, stats.LockWait = c.sampler.percentiles()
+	}
+	return stats
+}
+
+// GroupStats holds the usage statistics of a group of keys.
+type GroupStats struct {
+	// Len is the number of live entries in the group.
+	Len int
+	// Cost is the total cost of the live entries in the group, as weighed by WithCost, e.g. their estimated size in
+	// bytes with a nil weigher. Without WithCost every entry costs 1.
+	Cost int64
+	// Hits is the number of lookups of keys in the group that found a live entry.
+	Hits uint64
+	// Misses is the number of lookups of keys in the group that found no entry or an expired one.
+	Misses uint64
+	// Evictions is the number of entries of the group evicted to make room for new ones.
+	Evictions uint64
+}
+
+// HitRatio returns the ratio of hits to all lookups, or 0 if there were no lookups.
+func (s GroupStats) HitRatio() float64 {
+	return Stats{Hits: s.Hits, Misses: s.Misses}.HitRatio()
+}
+
+type groupCounters struct {
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+// WithKeyGroups makes the cache keep usage statistics per group of keys, as returned by the given grouping function,
+// so the owners of a shared cache can see which team or feature uses its capacity. See PrefixGroup for grouping string
+// keys by prefix.
+func WithKeyGroups[K comparable, V any](group func(key K) string) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.group = group
+		c.groups = make(map[string]*groupCounters)
+	}
+}
+
+// PrefixGroup returns a grouping function for WithKeyGroups that groups string keys by the part before the first
+// occurrence of sep. Keys that don't contain sep form a group of their own.
+func PrefixGroup(sep string) func(key string) string {
+	return func(key string) string {
+		prefix, _, _ := strings.Cut(key, sep)
+		return prefix
+	}
+}
+
+// GroupStats returns the usage statistics of every group of keys. It returns nil unless WithKeyGroups is used.
+func (c *InMemoryCache[K, V]) GroupStats() map[string]GroupStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.group == nil {
+		return nil
+	}
+	stats := make(map[string]GroupStats, len(c.groups))
+	for name, counters := range c.groups {
+		stats[name] = GroupStats{Hits: counters.hits, Misses: counters.misses, Evictions: counters.evictions}
+	}
+	for entry := c.list.Front(); entry != nil; entry = entry.Next() {
+		if c.expired(entry) || entry.err != nil {
+			continue
+		}
+		name := c.group(entry.key)
+		s := stats[name]
+		s.Len++
+		s.Cost += entry.cost
+		stats[name] = s
+	}
+	return stats
+}
+
+// countHit records a hit for the given key. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) countHit(key K) {
+	c.hits.Add(1)
+	if c.group != nil {
+		c.groupCounters(key).hits++
+	}
+}
+
+// countMiss records a miss for the given key. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) countMiss(key K) {
+	c.misses.Add(1)
+	if c.group != nil {
+		c.groupCounters(key).misses++
+	}
+}
+
+// countEviction records the eviction of the given key. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) countEviction(key K) {
+	c.evictions++
+	if c.group != nil {
+		c.groupCounters(key).evictions++
+	}
+}
+
+func (c *InMemoryCache[K, V]) groupCounters(key K) *groupCounters {
+	name := c.group(key)
+	counters, ok := c.groups[name]
+	if !ok {
+		counters = &groupCounters{}
+		c.groups[name] = counters
+	}
+	return counters
+}
@@ -0,0 +1,107 @@
+package ugulru_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryCache_Stats(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+	assert.Equal(t, 0.0, cache.Stats().HitRatio())
+
+	cache.Put("key1", 1)
+	cache.Get("key1")
+	cache.Get("key2")
+	cache.Load("key1", func() (int, error) { return 1, nil })
+	cache.Load("key3", func() (int, error) { return 3, nil })
+
+	stats := cache.Stats()
+	assert.Equal(t, uint64(2), stats.Hits)
+	assert.Equal(t, uint64(2), stats.Misses)
+	assert.Equal(t, 2, stats.Len)
+	assert.Equal(t, 2, stats.Capacity)
+	assert.Equal(t, 0.5, stats.HitRatio())
+}
+
+func TestWithSampling(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(2, 5*time.Minute, ugulru.WithSampling[string, int](1))
+	stats := cache.Stats()
+	assert.Zero(t, stats.Latency)
+
+	cache.Load("key1", func() (int, error) {
+		time.Sleep(10 * time.Millisecond)
+		return 1, nil
+	})
+	stats = cache.Stats()
+	assert.GreaterOrEqual(t, stats.Latency.P50, 10*time.Millisecond)
+	assert.Less(t, stats.LockWait.P50, 10*time.Millisecond)
+
+	// Operations running concurrently with a slow Load wait for the lock
+	go cache.Load("key2", func() (int, error) {
+		time.Sleep(50 * time.Millisecond)
+		return 2, nil
+	})
+	time.Sleep(10 * time.Millisecond)
+	cache.Get("key1")
+	stats = cache.Stats()
+	assert.GreaterOrEqual(t, stats.LockWait.P99, 20*time.Millisecond)
+}
+
+func TestWithSampling_Disabled(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+	cache.Put("key1", 1)
+	cache.Get("key1")
+	stats := cache.Stats()
+	assert.Zero(t, stats.Latency)
+	assert.Zero(t, stats.LockWait)
+}
+
+func TestInMemoryCache_StatsEvictions(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+	cache.Put("key3", 3)
+	cache.Remove("key2") // removals are not evictions
+	assert.Equal(t, uint64(1), cache.Stats().Evictions)
+}
+
+func TestWithKeyGroups(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(3, 5*time.Minute, ugulru.WithKeyGroups[string, int](ugulru.PrefixGroup(":")))
+	cache.Put("user:1", 1)
+	cache.Put("user:2", 2)
+	cache.Put("order:1", 3)
+	cache.Get("user:1")
+	cache.Get("user:3")
+	cache.Get("order:1")
+	cache.Get("plain")
+	cache.Put("order:2", 4) // evicts user:2
+
+	assert.Equal(t, map[string]ugulru.GroupStats{
+		"user":  {Len: 1, Cost: 1, Hits: 1, Misses: 1, Evictions: 1},
+		"order": {Len: 2, Cost: 2, Hits: 1},
+		"plain": {Misses: 1},
+	}, cache.GroupStats())
+	assert.Equal(t, 0.5, cache.GroupStats()["user"].HitRatio())
+
+	// Groups are only tracked when enabled
+	assert.Nil(t, ugulru.NewInMemoryCache[string, int](2, time.Minute).GroupStats())
+}
+
+func TestWithKeyGroups_Cost(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(10, 5*time.Minute,
+		ugulru.WithKeyGroups[string, string](ugulru.PrefixGroup(":")),
+		ugulru.WithCost(func(_ string, value string) int64 { return int64(len(value)) }, 100),
+	)
+	cache.Put("user:1", "abc")
+	cache.Put("user:2", "de")
+	cache.Put("order:1", "fghij")
+	cache.Put("user:2", "d") // updates are weighed again
+	cache.Remove("order:1")
+
+	stats := cache.GroupStats()
+	assert.Equal(t, int64(4), stats["user"].Cost)
+	assert.Equal(t, int64(0), stats["order"].Cost)
+}
@@ -0,0 +1,69 @@
+package ugulru
+
+import "context"
+
+// PutTagged works like Put but attaches the given tags to the entry, so it can later be invalidated together with all
+// the other entries carrying one of the tags with InvalidateTag. Writing the entry again with Put drops its tags.
+func (c *InMemoryCache[K, V]) PutTagged(key K, value V, tags ...string) {
+	c.throttle()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.writeBacking(context.Background(), key, value); err != nil {
+		c.remove(key)
+		return
+	}
+	c.store(key, value, func(entry *entry[K, V]) {
+		c.tag(entry, tags)
+	})
+}
+
+// InvalidateTag removes all the entries carrying the given tag and returns the number of removed entries.
+func (c *InMemoryCache[K, V]) InvalidateTag(tag string) (removed int) {
+	c.throttle()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.tags[tag] {
+		removed++
+		_ = c.deleteThrough(context.Background(), key)
+	}
+	if c.overflow != nil {
+		for _, key := range c.overflowedTagged(tag) {
+			removed++
+			_ = c.deleteThrough(context.Background(), key)
+		}
+	}
+	return removed
+}
+
+// tag attaches the given tags to the given entry. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) tag(entry *entry[K, V], tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+	if c.tags == nil {
+		c.tags = make(map[string]map[K]struct{})
+	}
+	for _, tag := range tags {
+		keys, ok := c.tags[tag]
+		if !ok {
+			keys = make(map[K]struct{})
+			c.tags[tag] = keys
+		}
+		keys[entry.key] = struct{}{}
+	}
+	entry.tags = append(entry.tags, tags...)
+}
+
+// untag detaches all the tags from the given entry. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) untag(entry *entry[K, V]) {
+	for _, tag := range entry.tags {
+		keys := c.tags[tag]
+		delete(keys, entry.key)
+		if len(keys) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+	entry.tags = nil
+}
@@ -0,0 +1,45 @@
+package ugulru_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryCache_InvalidateTag(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, string](10, 5*time.Minute)
+	cache.PutTagged("page:1", "<p>1</p>", "user:1", "product:1")
+	cache.PutTagged("page:2", "<p>2</p>", "product:1")
+	cache.PutTagged("page:3", "<p>3</p>", "user:2")
+	cache.Put("page:4", "<p>4</p>")
+
+	assert.Equal(t, 2, cache.InvalidateTag("product:1"))
+	_, ok := cache.Get("page:1")
+	assert.False(t, ok)
+	_, ok = cache.Get("page:2")
+	assert.False(t, ok)
+	_, ok = cache.Get("page:3")
+	assert.True(t, ok)
+
+	// The tags of removed entries are cleaned up
+	assert.Equal(t, 0, cache.InvalidateTag("user:1"))
+	assert.Equal(t, 0, cache.InvalidateTag("unknown"))
+
+	// Rewriting an entry drops its tags
+	cache.Put("page:3", "<p>3 v2</p>")
+	assert.Equal(t, 0, cache.InvalidateTag("user:2"))
+	_, ok = cache.Get("page:3")
+	assert.True(t, ok)
+}
+
+func TestInMemoryCache_InvalidateTag_Eviction(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](1, 5*time.Minute)
+	cache.PutTagged("key1", 1, "tag")
+	cache.PutTagged("key2", 2, "tag") // evicts key1
+
+	assert.Equal(t, 1, cache.InvalidateTag("tag"))
+	_, ok := cache.Get("key2")
+	assert.False(t, ok)
+}
@@ -0,0 +1,57 @@
+package ugulru
+
+// TieredCache is a Cache composed of two caches: a small, fast first level, typically an InMemoryCache, in front of a
+// larger or shared second level, such as a Redis cache. Reads check the first level and then the second one, copying
+// second-level hits to the first level, while writes and removals go to both levels. Each level keeps its own TTL, so
+// the first level can use a shorter TTL to bound how long it serves values changed in the second level by others.
+type TieredCache[K comparable, V any] struct {
+	l1, l2 Cache[K, V]
+}
+
+var _ Cache[string, any] = (*TieredCache[string, any])(nil)
+
+// NewTieredCache creates a new tiered cache with the given first and second levels.
+func NewTieredCache[K comparable, V any](l1, l2 Cache[K, V]) *TieredCache[K, V] {
+	return &TieredCache[K, V]{l1: l1, l2: l2}
+}
+
+// Get retrieves the value from the first level, or from the second level, in which case the value is copied to the
+// first level.
+func (t *TieredCache[K, V]) Get(key K) (V, bool) {
+	if value, ok := t.l1.Get(key); ok {
+		return value, true
+	}
+	value, ok := t.l2.Get(key)
+	if ok {
+		t.l1.Put(key, value)
+	}
+	return value, ok
+}
+
+// Put inserts or updates the value in both levels, the second level first, so a concurrent Get can't copy an older
+// value from the second level after the first level was written.
+func (t *TieredCache[K, V]) Put(key K, value V) {
+	t.l2.Put(key, value)
+	t.l1.Put(key, value)
+}
+
+// Remove deletes the entry from both levels, the second level first.
+func (t *TieredCache[K, V]) Remove(key K) {
+	t.l2.Remove(key)
+	t.l1.Remove(key)
+}
+
+// RemoveExpired removes all expired entries from both levels.
+func (t *TieredCache[K, V]) RemoveExpired() {
+	t.l1.RemoveExpired()
+	t.l2.RemoveExpired()
+}
+
+// Load retrieves the value for the given key from the first level, then from the second level, and calls the loader
+// only when both levels miss. The loaded value is stored in both levels. Each level applies its own Load semantics,
+// such as deduplicating concurrent loads or caching errors.
+func (t *TieredCache[K, V]) Load(key K, loader func() (V, error)) (V, error) {
+	return t.l1.Load(key, func() (V, error) {
+		return t.l2.Load(key, loader)
+	})
+}
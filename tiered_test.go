@@ -0,0 +1,73 @@
+package ugulru_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTieredCache(t *testing.T) {
+	l1 := ugulru.NewInMemoryCache[string, int](2, time.Minute)
+	l2 := ugulru.NewInMemoryCache[string, int](10, time.Hour)
+	cache := ugulru.NewTieredCache[string, int](l1, l2)
+
+	cache.Put("key1", 1)
+	_, ok := l1.Get("key1")
+	assert.True(t, ok)
+	_, ok = l2.Get("key1")
+	assert.True(t, ok)
+
+	// Second-level hits are copied to the first level
+	l2.Put("key2", 2)
+	value, ok := cache.Get("key2")
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+	_, ok = l1.Get("key2")
+	assert.True(t, ok)
+
+	cache.Remove("key1")
+	_, ok = l1.Get("key1")
+	assert.False(t, ok)
+	_, ok = l2.Get("key1")
+	assert.False(t, ok)
+	_, ok = cache.Get("key1")
+	assert.False(t, ok)
+}
+
+func TestTieredCache_Load(t *testing.T) {
+	l1 := ugulru.NewInMemoryCache[string, int](2, time.Minute)
+	l2 := ugulru.NewInMemoryCache[string, int](10, time.Hour)
+	cache := ugulru.NewTieredCache[string, int](l1, l2)
+
+	loads := 0
+	loader := func() (int, error) {
+		loads++
+		return 1, nil
+	}
+
+	value, err := cache.Load("key1", loader)
+	require.NoError(t, err)
+	assert.Equal(t, 1, value)
+	_, ok := l1.Get("key1")
+	assert.True(t, ok)
+	_, ok = l2.Get("key1")
+	assert.True(t, ok)
+
+	// A first-level miss is served by the second level
+	l1.Remove("key1")
+	value, err = cache.Load("key1", loader)
+	require.NoError(t, err)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, 1, loads)
+
+	_, err = cache.Load("key2", func() (int, error) {
+		return 0, errors.New("failed")
+	})
+	assert.Error(t, err)
+	_, ok = l2.Get("key2")
+	assert.False(t, ok)
+}
@@ -0,0 +1,56 @@
+package ugulru
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrLoadTimeout is returned by the loading methods when the loader doesn't complete within the timeout set with
+// WithLoadTimeout.
+var ErrLoadTimeout = errors.New("ugulru: loader timed out")
+
+// WithLoadTimeout bounds the execution time of loaders called by Load, LoadContext and LoadWithUsage, as well as
+// background refreshes. Loaders that take a context get a context with the corresponding deadline. If the loader
+// doesn't return in time, the load fails with ErrLoadTimeout and the late result of the loader is discarded.
+func WithLoadTimeout[K comparable, V any](timeout time.Duration) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.loadTimeout = timeout
+	}
+}
+
+// callWithTimeout calls the given loader, bounding its execution time by the load timeout of the cache, if any.
+func (c *InMemoryCache[K, V]) callWithTimeout(ctx context.Context, loader func(ctx context.Context) (V, error)) (V, error) {
+	return withTimeout(ctx, c.loadTimeout, loader)
+}
+
+// withTimeout calls the given loader, bounding its execution time by the given timeout if it is positive.
+func withTimeout[T any](ctx context.Context, timeout time.Duration, loader func(ctx context.Context) (T, error)) (T, error) {
+	if timeout <= 0 {
+		return protect(ctx, loader)
+	}
+
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, ErrLoadTimeout)
+	defer cancel()
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := protect(ctx, loader)
+		done <- result{value: value, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil && errors.Is(context.Cause(ctx), ErrLoadTimeout) {
+			return r.value, ErrLoadTimeout
+		}
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero T
+		return zero, context.Cause(ctx)
+	}
+}
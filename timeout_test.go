@@ -0,0 +1,50 @@
+package ugulru_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLoadTimeout(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(2, 5*time.Minute, ugulru.WithLoadTimeout[string, int](20*time.Millisecond))
+
+	t.Run("Test loader ignoring the context", func(t *testing.T) {
+		start := time.Now()
+		_, err := cache.Load("key1", func() (int, error) {
+			time.Sleep(200 * time.Millisecond)
+			return 1, nil
+		})
+		assert.ErrorIs(t, err, ugulru.ErrLoadTimeout)
+		assert.Less(t, time.Since(start), 100*time.Millisecond)
+		_, ok := cache.Get("key1")
+		assert.False(t, ok)
+	})
+
+	t.Run("Test loader honoring the context", func(t *testing.T) {
+		_, err := cache.LoadContext(context.Background(), "key2", func(ctx context.Context) (int, error) {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+		assert.ErrorIs(t, err, ugulru.ErrLoadTimeout)
+	})
+
+	t.Run("Test cancelled parent context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := cache.LoadContext(ctx, "key3", func(ctx context.Context) (int, error) {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("Test fast loader", func(t *testing.T) {
+		value, err := cache.Load("key4", func() (int, error) { return 4, nil })
+		assert.NoError(t, err)
+		assert.Equal(t, 4, value)
+	})
+}
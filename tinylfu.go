@@ -0,0 +1,197 @@
+package ugulru
+
+import (
+	"cmp"
+	"hash/maphash"
+	"math/bits"
+)
+
+// WithTinyLFU puts a W-TinyLFU admission policy in front of the cache, like the one of Caffeine and Ristretto. The
+// access frequencies of all the requested keys, including misses, are estimated with a compact count-min sketch that
+// is periodically halved, so it favors recent popularity. New entries enter a small LRU window holding 1% of the
+// capacity; when the window overflows, its least recently used entry competes with the least recently used entry of
+// the rest of the cache, and the one with the lower estimated frequency is evicted. This keeps low-frequency newcomers
+// from evicting higher-frequency residents while still letting bursts of new keys through the window. Finding the
+// entries that compete may take time proportional to the number of entries. The sketch is sized by the capacity and
+// resized with it by Resize, so it panics with NewInMemoryCacheWithMaxBytes.
+func WithTinyLFU[K comparable, V any]() Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		if c.unbounded() {
+			panic("ugulru: WithTinyLFU requires a bounded capacity")
+		}
+		c.sketch = newSketch(c.capacity)
+	}
+}
+
+// admissionVictim returns the entry to evict according to the W-TinyLFU policy, admitting the window candidate to
+// the main part of the cache if it wins, or nil if all the entries are pinned. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) admissionVictim() *entry[K, V] {
+	var candidate, victim *entry[K, V]
+	for e := c.evictable(c.list.Back()); e != nil; e = c.evictable(e.Prev()) {
+		if !e.windowed {
+			victim = cmp.Or(victim, e)
+		} else {
+			candidate = cmp.Or(candidate, e)
+		}
+		if candidate != nil && victim != nil {
+			break
+		}
+	}
+	if candidate == nil || victim == nil {
+		return c.evictable(c.list.Back())
+	}
+	if c.windowLen < c.windowCap() {
+		return victim
+	}
+	admitted := candidate
+	if c.sketch.estimate(hashKey(admitted.key)) <= c.sketch.estimate(hashKey(victim.key)) {
+		return candidate
+	}
+	admitted.windowed = false
+	c.windowLen--
+	return victim
+}
+
+// spill moves the least recently used entries of the window to the main part of the cache while the window
+// overflows. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) spill() {
+	seen := 0
+	for e := c.list.Front(); e != nil && c.windowLen > c.windowCap(); e = e.Next() {
+		if e.windowed {
+			if seen++; seen > c.windowCap() {
+				e.windowed = false
+				c.windowLen--
+			}
+		}
+	}
+}
+
+// windowCap returns the capacity of the admission window, relative to the number of entries if the capacity is
+// unbounded.
+func (c *InMemoryCache[K, V]) windowCap() int {
+	if c.unbounded() {
+		return max(c.list.Len()/100, 1)
+	}
+	return max(c.capacity/100, 1)
+}
+
+// sketch is a count-min sketch of small saturating counters estimating access frequencies. All the counters are
+// halved after a number of increments proportional to the capacity of the cache, so old accesses fade out.
+type sketch struct {
+	rows      [4][]uint8
+	mask      uint64
+	additions int
+	resetAt   int
+}
+
+// sketchMax is the value at which the counters of the sketch saturate.
+const sketchMax = 15
+
+func newSketch(capacity int) *sketch {
+	width := sketchWidth(capacity)
+	s := &sketch{mask: uint64(width - 1), resetAt: 10 * max(capacity, 16)}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+// sketchWidth returns the number of counters in every row of the sketch for the given capacity.
+func sketchWidth(capacity int) int {
+	// Four counters per entry in every row keep the collisions low
+	return 1 << bits.Len(uint(max(4*capacity, 64)-1))
+}
+
+// resize resizes the sketch for the given capacity, keeping the recorded frequencies: when the rows grow, every new
+// counter starts from the counter it was folded into, and when they shrink, the counters folded together are summed.
+func (s *sketch) resize(capacity int) {
+	s.resetAt = 10 * max(capacity, 16)
+	width := sketchWidth(capacity)
+	if width == len(s.rows[0]) {
+		return
+	}
+	mask := uint64(width - 1)
+	for i, row := range s.rows {
+		resized := make([]uint8, width)
+		if width > len(row) {
+			for j := range resized {
+				resized[j] = row[uint64(j)&s.mask]
+			}
+		} else {
+			for j, counter := range row {
+				resized[uint64(j)&mask] = min(resized[uint64(j)&mask]+counter, sketchMax)
+			}
+		}
+		s.rows[i] = resized
+	}
+	s.mask = mask
+}
+
+// increment records an access to the key with the given hash.
+func (s *sketch) increment(hash uint64) {
+	for i := range s.rows {
+		if counter := &s.rows[i][s.index(hash, i)]; *counter < sketchMax {
+			*counter++
+		}
+	}
+	s.additions++
+	if s.additions >= s.resetAt {
+		s.reset()
+	}
+}
+
+// estimate returns the estimated access frequency of the key with the given hash.
+func (s *sketch) estimate(hash uint64) uint8 {
+	estimate := uint8(sketchMax)
+	for i := range s.rows {
+		estimate = min(estimate, s.rows[i][s.index(hash, i)])
+	}
+	return estimate
+}
+
+// index returns the index of the counter of the key with the given hash in the given row.
+func (s *sketch) index(hash uint64, row int) uint64 {
+	hash += uint64(row) * (hash>>32 | 1) * 0x9e3779b97f4a7c15
+	return (hash ^ hash>>29) & s.mask
+}
+
+// reset halves all the counters.
+func (s *sketch) reset() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.additions /= 2
+}
+
+var hashSeed = maphash.MakeSeed()
+
+// hashKey returns a hash of the given key.
+func hashKey[K comparable](key K) uint64 {
+	switch k := any(key).(type) {
+	case string:
+		return maphash.String(hashSeed, k)
+	case int:
+		return mix(uint64(k))
+	case int64:
+		return mix(uint64(k))
+	case uint64:
+		return mix(k)
+	case int32:
+		return mix(uint64(k))
+	case uint32:
+		return mix(uint64(k))
+	}
+	return maphash.Comparable(hashSeed, key)
+}
+
+// mix scrambles the bits of an integer key, so consecutive keys don't map to consecutive counters.
+func mix(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
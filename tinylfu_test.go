@@ -0,0 +1,101 @@
+package ugulru_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTinyLFU(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(100, 5*time.Minute, ugulru.WithTinyLFU[string, int]())
+
+	for i := range 100 {
+		cache.Put(fmt.Sprintf("hot%d", i), i)
+	}
+	for range 3 {
+		for i := range 100 {
+			cache.Get(fmt.Sprintf("hot%d", i))
+		}
+	}
+
+	// One-hit wonders don't get past the window
+	for i := range 1000 {
+		cache.Put(fmt.Sprintf("scan%d", i), i)
+	}
+	assert.Equal(t, 100, cache.Stats().Len)
+
+	resident := 0
+	for i := range 100 {
+		if _, ok := cache.Get(fmt.Sprintf("hot%d", i)); ok {
+			resident++
+		}
+	}
+	assert.GreaterOrEqual(t, resident, 99)
+
+	// A key that becomes popular is admitted
+	for range 5 {
+		cache.Get("new")
+	}
+	cache.Put("new", 1)
+	cache.Put("other", 2)
+	_, ok := cache.Get("new")
+	assert.True(t, ok)
+}
+
+func TestWithTinyLFURewrite(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(500, 5*time.Minute, ugulru.WithTinyLFU[string, int]())
+	for i := range 5 {
+		cache.Put(fmt.Sprint(i), i)
+	}
+	assert.Equal(t, 5, cache.WindowLen())
+
+	// Rewritten entries leave the window
+	for i := range 5 {
+		cache.Put(fmt.Sprint(i), i+1)
+	}
+	assert.Equal(t, 0, cache.WindowLen())
+	for i := range 5 {
+		cache.Remove(fmt.Sprint(i))
+	}
+	assert.Equal(t, 0, cache.WindowLen())
+
+	// The window still spills its entries when it overflows
+	for i := range 10 {
+		cache.Put(fmt.Sprint(i), i)
+	}
+	assert.Equal(t, 5, cache.WindowLen())
+}
+
+func TestWithTinyLFUResize(t *testing.T) {
+	cache := ugulru.NewInMemoryCache(10, 5*time.Minute, ugulru.WithTinyLFU[string, int]())
+	for range 5 {
+		cache.Get("hot")
+	}
+	assert.Equal(t, 64, cache.SketchWidth())
+
+	// The sketch follows the capacity and keeps the recorded frequencies
+	cache.Resize(1000)
+	assert.Equal(t, 4096, cache.SketchWidth())
+	assert.GreaterOrEqual(t, cache.Estimate("hot"), uint8(5))
+
+	cache.Resize(10)
+	assert.Equal(t, 64, cache.SketchWidth())
+	assert.GreaterOrEqual(t, cache.Estimate("hot"), uint8(5))
+}
+
+func TestHashKey(t *testing.T) {
+	type point struct {
+		x, y int
+		name string
+	}
+	key := point{1, 2, "a"}
+	assert.Equal(t, ugulru.HashKey(key), ugulru.HashKey(point{1, 2, "a"}))
+	assert.NotEqual(t, ugulru.HashKey(key), ugulru.HashKey(point{2, 1, "a"}))
+
+	// Hashing keys of any type doesn't allocate
+	allocs := testing.AllocsPerRun(100, func() { ugulru.HashKey(key) })
+	assert.Zero(t, allocs)
+}
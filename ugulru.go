@@ -1,8 +1,11 @@
 package ugulru
 
 import (
-	"container/list"
+	"context"
+	"crypto/cipher"
+	"math/rand/v2"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -17,132 +20,707 @@ type Cache[K comparable, V any] interface {
 // InMemoryCache is an in-memory LRU (Least Recently Used) cache that stores key-value pairs with a fixed capacity and
 // a time-to-live (TTL) duration.
 type InMemoryCache[K comparable, V any] struct {
-	cache    map[K]*list.Element
-	list     *list.List
+	cache    map[K]*entry[K, V]
+	list     linkedList[entry[K, V], *entry[K, V]]
 	capacity int
 	ttl      time.Duration
-	mu       sync.Mutex
+	tti      time.Duration
+	epoch    time.Time
+	gen      uint64
+	mu       sync.RWMutex
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions uint64
+	group     func(key K) string
+	groups    map[string]*groupCounters
+
+	sizeHint int
+	chain    []LoaderStage[K, V]
+	sliding  bool
+	sampler  *sampler
+	halfLife time.Duration
+	jitter   float64
+	stale    time.Duration
+
+	staleIfError time.Duration
+	flagStale    bool
+
+	refreshAfter  time.Duration
+	refreshLoader func(key K) (V, error)
+
+	isNegative   func(err error) bool
+	negativeTTL  time.Duration
+	ttlFromValue func(value V) time.Duration
+
+	penaltyWindow int
+	loadTimeout   time.Duration
+	softTTL       time.Duration
+	breaker       *breaker[K]
+	beforeEvict   func(key K, value V) bool
+	evictRetries  int
+
+	quiesced bool
+	pending  []pendingWrite[K, V]
+
+	tags           map[string]map[K]struct{}
+	warmup         time.Duration
+	warmupFrom     float64
+	equal          func(a, b V) bool
+	edf            bool
+	prioritized    bool
+	lfu            bool
+	protectedShare float64
+	protectedLen   int
+	sketch         *sketch
+	windowLen      int
+	free           []*entry[K, V]
+	sharedReads    bool
+	promoteAfter   float64
+	snapshotPath   string
+	snapshotEvery  time.Duration
+	codec          Codec[V]
+	aead           cipher.AEAD
+	overflow       Store[K, V]
+	backing        Store[K, V]
+	behind         *writeBehind[K, V]
+	overflowed     map[K]spilled
+	snapshotsDone  chan struct{}
+	closed         chan struct{}
+	closeOnce      sync.Once
+	moves          uint64
+	reads          *readBuffer[K, V]
+	ghosts         []*ghost[K]
+	weigher        func(key K, value V) int64
+	maxCost        int64
+	cost           int64
+	background     barrier
+	calls          map[K]*loadCall[V]
+}
+
+// loadCall is a load in progress shared by concurrent LoadContext calls for the same key.
+type loadCall[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
 }
 
 type entry[K comparable, V any] struct {
 	key       K
 	value     V
-	timestamp time.Time
+	timestamp int64 // milliseconds since the epoch of the cache
+	accessed  int64 // milliseconds since the epoch of the cache
+	gen       uint64
+	source    string
+	freq      float64
+	freqAt    int64 // milliseconds since the epoch of the cache
+	jitter    float64
+	ttl       time.Duration // overrides the TTL of the cache if not zero
+	soft      time.Duration // overrides the soft TTL of the cache if not zero
+	err       error
+	penalty   time.Duration
+	tags      []string
+	pinned    bool
+	priority  int
+	cost      int64
+	protected bool
+	windowed  bool
+	moved     uint64
+
+	refreshing bool
+
+	listLinks[entry[K, V]]
 }
 
-// NewInMemoryCache creates a new in-memory cache with the specified capacity and TTL duration.
-func NewInMemoryCache[K comparable, V any](capacity int, ttl time.Duration) *InMemoryCache[K, V] {
-	return &InMemoryCache[K, V]{
-		cache:    make(map[K]*list.Element),
-		list:     list.New(),
+// NewInMemoryCache creates a new in-memory cache with the specified capacity and TTL duration. The TTL is counted from
+// the time an entry was last written (expire-after-write); see WithExpireAfterAccess and WithSlidingExpiration for
+// other expiration policies.
+func NewInMemoryCache[K comparable, V any](capacity int, ttl time.Duration, opts ...Option[K, V]) *InMemoryCache[K, V] {
+	c := &InMemoryCache[K, V]{
 		capacity: capacity,
 		ttl:      ttl,
+		epoch:    time.Now(),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.list.init()
+	c.cache = make(map[K]*entry[K, V], c.sizeHint)
+	if c.sharedReads && c.sharedReadsSupported() {
+		c.reads = new(readBuffer[K, V])
+	}
+	c.startSnapshots()
+	return c
 }
 
 // Get retrieves a value from the cache based on the given key. It returns the value and a boolean indicating whether
 // the key exists in the cache.
 func (c *InMemoryCache[K, V]) Get(key K) (V, bool) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	if c.reads != nil {
+		return c.getShared(key)
+	}
+	defer c.unlock(c.lock())
 
-	var zero V
-	if elem, ok := c.cache[key]; ok {
-		entry := elem.Value.(*entry[K, V])
-		if time.Since(entry.timestamp) > c.ttl {
-			c.list.Remove(elem)
-			return zero, false
-		}
-		c.list.MoveToFront(elem)
+	if entry, ok := c.get(key); ok {
 		return entry.value, true
 	}
+	var zero V
 	return zero, false
 }
 
-// Put inserts or updates the value associated with the given key.
-func (c *InMemoryCache[K, V]) Put(key K, value V) {
+// GetWithGeneration works like Get but also returns the generation of the entry. The generation is a number that
+// increases monotonically across the whole cache every time an entry is written, so callers can detect that the entry
+// was replaced between two reads even if the value is the same.
+func (c *InMemoryCache[K, V]) GetWithGeneration(key K) (V, uint64, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if elem, ok := c.cache[key]; ok {
-		entry := elem.Value.(*entry[K, V])
-		entry.value = value
-		entry.timestamp = time.Now()
-		c.list.MoveToFront(elem)
-		return
+	if entry, ok := c.get(key); ok {
+		return entry.value, entry.gen, true
 	}
+	var zero V
+	return zero, 0, false
+}
 
-	if c.list.Len() >= c.capacity {
-		elem := c.list.Back()
-		entry := elem.Value.(*entry[K, V])
-		delete(c.cache, entry.key)
-		c.list.Remove(elem)
+// GetWithExpiry works like Get but also returns the time at which the entry expires, so callers can propagate the
+// remaining lifetime of the entry downstream. The expiry has millisecond resolution.
+func (c *InMemoryCache[K, V]) GetWithExpiry(key K) (V, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.get(key); ok {
+		return entry.value, c.timeOf(c.expiresAt(entry)), true
 	}
+	var zero V
+	return zero, time.Time{}, false
+}
+
+// Put inserts or updates the value associated with the given key.
+func (c *InMemoryCache[K, V]) Put(key K, value V) {
+	c.throttle()
+	defer c.unlock(c.lock())
 
-	entry := &entry[K, V]{key: key, value: value, timestamp: time.Now()}
-	elem := c.list.PushFront(entry)
-	c.cache[key] = elem
+	_, _ = c.writeThrough(context.Background(), key, value, nil)
 }
 
-// Remove deletes the entry with the given key from the cache.
-func (c *InMemoryCache[K, V]) Remove(key K) {
+// Touch resets the lifetime of the entry with the given key without changing its value or its recency. It returns
+// false if the key doesn't exist in the cache or has already expired.
+func (c *InMemoryCache[K, V]) Touch(key K) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if elem, ok := c.cache[key]; ok {
-		delete(c.cache, key)
-		c.list.Remove(elem)
+	entry, ok := c.cache[key]
+	if !ok {
+		return false
 	}
+	if c.expired(entry) {
+		return false
+	}
+	entry.timestamp = c.clock()
+	entry.accessed = entry.timestamp
+	return true
+}
+
+// Remove deletes the entry with the given key from the cache.
+func (c *InMemoryCache[K, V]) Remove(key K) {
+	c.throttle()
+	defer c.unlock(c.lock())
+
+	_ = c.deleteThrough(context.Background(), key)
 }
 
 // Load retrieves the value from the cache based on the given key. If the key exists in the cache and has not expired,
 // the value is returned. Otherwise, the loader function is called to load the value, which is then stored in the cache
 // and returned.
 func (c *InMemoryCache[K, V]) Load(key K, loader func() (V, error)) (V, error) {
+	defer c.unlock(c.lock())
+
+	return c.load(key, loader)
+}
+
+// load implements Load. It must be called with the lock held, which is kept while the loader is running.
+func (c *InMemoryCache[K, V]) load(key K, loader func() (V, error)) (V, error) {
+	if value, ok, err := c.cached(key, loader); ok {
+		return value, err
+	}
+	if value, ok := c.readOverflow(key); ok {
+		return value, nil
+	}
+
+	start := time.Now()
+	value, err := c.callLoader(context.Background(), key, func(context.Context) (V, error) {
+		return loader()
+	})
+	return c.loaded(key, value, err, time.Since(start))
+}
+
+// LoadContext works like Load but passes the given context to the loader, so it can carry deadlines, cancellation and
+// tracing. Unlike Load, the cache is not locked while the loader is running; concurrent calls for the same key share
+// a single in-flight load instead. The loader runs with the context of the call that started the load, and callers
+// waiting on a shared load return early with the context error if their context is done first.
+func (c *InMemoryCache[K, V]) LoadContext(ctx context.Context, key K, loader func(ctx context.Context) (V, error)) (V, error) {
+	c.mu.Lock()
+	background := func() (V, error) {
+		return loader(context.WithoutCancel(ctx))
+	}
+	if value, ok, err := c.cached(key, background); ok {
+		c.mu.Unlock()
+		return value, err
+	}
+	if value, ok := c.readOverflow(key); ok {
+		c.mu.Unlock()
+		return value, nil
+	}
+	if call, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.value, call.err
+		case <-ctx.Done():
+			var zero V
+			return zero, ctx.Err()
+		}
+	}
+	call := &loadCall[V]{done: make(chan struct{})}
+	if c.calls == nil {
+		c.calls = make(map[K]*loadCall[V])
+	}
+	c.calls[key] = call
+	c.mu.Unlock()
+
+	start := time.Now()
+	value, err := c.callLoader(ctx, key, loader)
+
+	c.mu.Lock()
+	call.value, call.err = c.loaded(key, value, err, time.Since(start))
+	delete(c.calls, key)
+	c.mu.Unlock()
+	close(call.done)
+
+	return call.value, call.err
+}
+
+// RemoveExpired removes all expired entries from the cache.
+func (c *InMemoryCache[K, V]) RemoveExpired() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if elem, ok := c.cache[key]; ok {
-		entry := elem.Value.(*entry[K, V])
-		if time.Since(entry.timestamp) > c.ttl {
-			c.list.Remove(elem)
-		} else {
-			c.list.MoveToFront(elem)
-			return entry.value, nil
+	if c.quiesced {
+		return
+	}
+	for entry := c.list.Back(); entry != nil; {
+		prev := entry.Prev()
+		if c.expired(entry) && !c.retained(entry) {
+			c.removeEntry(entry)
 		}
+		entry = prev
+	}
+	if c.overflow != nil {
+		c.removeExpiredOverflow()
 	}
+}
 
-	value, err := loader()
+// Resize changes the capacity of the cache. If the new capacity is smaller than the number of entries, the least
+// recently used entries are evicted. It panics if newCapacity is not positive.
+func (c *InMemoryCache[K, V]) Resize(newCapacity int) {
+	if newCapacity <= 0 {
+		panic("ugulru: capacity must be positive")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.capacity = newCapacity
+	if c.sketch != nil {
+		c.sketch.resize(newCapacity)
+		c.spill()
+	}
+	if !c.quiesced {
+		c.trim()
+	}
+}
+
+// SetTTL changes the time-to-live duration of the cache. The new TTL applies to all entries, including the ones that
+// are already in the cache: their expiry is recomputed from the time they were last written, so shortening the TTL
+// may expire existing entries immediately and extending it prolongs their lifetime.
+func (c *InMemoryCache[K, V]) SetTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ttl = ttl
+}
+
+// TTL returns the time-to-live duration of the cache.
+func (c *InMemoryCache[K, V]) TTL() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ttl
+}
+
+// Range calls fn for each live entry in the cache, from the most to the least recently used, until fn returns false.
+// Expired entries are skipped. Range does not affect the recency of the visited entries.
+//
+// The cache is locked for the whole iteration, so fn must not call any methods of the cache.
+func (c *InMemoryCache[K, V]) Range(fn func(key K, value V) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for entry := c.list.Front(); entry != nil; entry = entry.Next() {
+		if c.expired(entry) || entry.err != nil {
+			continue
+		}
+		if !fn(entry.key, entry.value) {
+			return
+		}
+	}
+}
+
+// nextGen returns the next entry generation. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) nextGen() uint64 {
+	c.gen++
+	return c.gen
+}
+
+// expired reports whether the given entry has outlived the TTL. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) expired(entry *entry[K, V]) bool {
+	return !entry.pinned && c.clock() > c.expiresAt(entry)
+}
+
+// expiresAt returns the time at which the given entry expires, in milliseconds since the epoch of the cache. It must
+// be called with the lock held.
+func (c *InMemoryCache[K, V]) expiresAt(entry *entry[K, V]) int64 {
+	var expiry int64
+	if entry.ttl < 0 {
+		// The entry was already expired when it was written
+		return entry.timestamp - 1
+	} else if entry.ttl > 0 {
+		expiry = entry.timestamp + entry.ttl.Milliseconds()
+	} else {
+		ttl := c.ttl + time.Duration(float64(c.ttl)*entry.jitter)
+		expiry = entry.timestamp + c.ramp(entry.timestamp, ttl).Milliseconds()
+	}
+	if c.tti > 0 {
+		expiry = min(expiry, entry.accessed+c.tti.Milliseconds())
+	}
+	return expiry
+}
+
+// clock returns the current time in milliseconds since the epoch of the cache. Entries store their timestamps in this
+// compact form rather than as time.Time.
+func (c *InMemoryCache[K, V]) clock() int64 {
+	return time.Since(c.epoch).Milliseconds()
+}
+
+// timeOf converts a time in milliseconds since the epoch of the cache to a time.Time.
+func (c *InMemoryCache[K, V]) timeOf(ms int64) time.Time {
+	return c.epoch.Add(time.Duration(ms) * time.Millisecond)
+}
+
+// valueTTL returns the TTL of an entry holding the given value, or zero if the TTL of the cache applies.
+func (c *InMemoryCache[K, V]) valueTTL(value V) time.Duration {
+	if c.ttlFromValue == nil {
+		return 0
+	}
+	if ttl := c.ttlFromValue(value); ttl > 0 {
+		return ttl
+	}
+	return -1
+}
+
+// randomJitter returns a random TTL adjustment for a written entry, as a fraction of the TTL.
+func (c *InMemoryCache[K, V]) randomJitter() float64 {
+	if c.jitter == 0 {
+		return 0
+	}
+	return (rand.Float64()*2 - 1) * c.jitter
+}
+
+// get returns the live entry for the given key and marks it as the most recently used. Expired entries are removed.
+// It must be called with the lock held.
+func (c *InMemoryCache[K, V]) get(key K) (*entry[K, V], bool) {
+	if c.sketch != nil {
+		c.sketch.increment(hashKey(key))
+	}
+	for _, ghost := range c.ghosts {
+		ghost.access(key)
+	}
+	entry, ok := c.cache[key]
+	if !ok {
+		c.countMiss(key)
+		return nil, false
+	}
+	if c.expired(entry) {
+		if !c.quiesced && !c.retained(entry) {
+			c.removeEntry(entry)
+		}
+		c.countMiss(key)
+		return nil, false
+	}
+	if entry.err != nil {
+		// Negative entries are only served by Load
+		c.countMiss(key)
+		return nil, false
+	}
+	c.countHit(key)
+	if !c.quiesced {
+		c.bump(entry)
+		entry.accessed = c.clock()
+		c.touchFrequency(entry, entry.accessed)
+		if c.sliding {
+			entry.timestamp = entry.accessed
+		}
+		if c.protectedShare > 0 {
+			c.promote(entry)
+		}
+		c.refreshAhead(entry)
+	}
+	return entry, true
+}
+
+// cached returns the value Load must return for the given key without calling the loader, if any: a live value, a
+// stale value being revalidated with the given loader, or the error of a negative entry. It must be called with the
+// lock held.
+func (c *InMemoryCache[K, V]) cached(key K, loader func() (V, error)) (V, bool, error) {
+	if entry, ok := c.get(key); ok {
+		c.refreshSoft(entry, loader)
+		return entry.value, true, nil
+	}
+	if value, ok := c.revalidate(key, loader); ok {
+		return value, true, nil
+	}
+	if err := c.negative(key); err != nil {
+		var zero V
+		return zero, true, err
+	}
+	var zero V
+	return zero, false, nil
+}
+
+// callLoader calls the given loader for the given key, unless the circuit breaker is open, bounding its execution time
+// by the load timeout of the cache. With WithWriteThrough, the store is read first.
+func (c *InMemoryCache[K, V]) callLoader(ctx context.Context, key K, loader func(ctx context.Context) (V, error)) (V, error) {
+	if c.backing != nil {
+		loader = c.readThrough(key, loader)
+	}
+	if c.breaker != nil && !c.breaker.allow(key) {
+		var zero V
+		return zero, ErrCircuitOpen
+	}
+	value, err := c.callWithTimeout(ctx, loader)
+	if c.breaker != nil {
+		c.breaker.record(key, err == nil || (c.isNegative != nil && c.isNegative(err)))
+	}
+	return value, err
+}
+
+// loaded stores the result of a loader call that took the given time and returns the value and the error Load must
+// return. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) loaded(key K, value V, err error, penalty time.Duration) (V, error) {
 	if err != nil {
+		if c.storeNegative(key, err) {
+			return value, err
+		}
+		if stale, ok := c.staleOnError(key); ok {
+			return stale, c.staleError(err)
+		}
 		return value, err
 	}
 
+	c.store(key, value, func(entry *entry[K, V]) {
+		entry.penalty = penalty
+	})
+	return value, nil
+}
+
+// store writes the value associated with the given key and calls apply, if not nil, with the written entry to set its
+// metadata, or buffers the write if the cache is quiesced, in which case apply is called when the write is replayed. It
+// returns the written entry, or nil if the write was buffered. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) store(key K, value V, apply func(*entry[K, V])) *entry[K, V] {
+	if c.quiesced {
+		c.buffer(pendingWrite[K, V]{key: key, value: value, apply: apply})
+		return nil
+	}
+	entry := c.put(key, value)
+	if apply != nil {
+		apply(entry)
+	}
+	return entry
+}
+
+// remove deletes the given key from the cache, or buffers the removal while the cache is quiesced. It must be called
+// with the lock held.
+func (c *InMemoryCache[K, V]) remove(key K) {
+	if c.quiesced {
+		c.buffer(pendingWrite[K, V]{key: key, remove: true})
+		return
+	}
+	if e, ok := c.cache[key]; ok {
+		c.removeEntry(e)
+	}
+	if c.overflow != nil {
+		c.dropOverflow(key)
+	}
+}
+
+// put inserts or updates the value associated with the given key. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) put(key K, value V) *entry[K, V] {
+	c.drainReads()
+	if c.overflow != nil {
+		c.dropOverflow(key)
+	}
+	var entry *entry[K, V]
+	if e, ok := c.cache[key]; ok {
+		entry = c.update(e, value)
+	} else {
+		entry = c.add(key, value)
+	}
+	if c.maxCost > 0 {
+		c.fit(entry)
+	}
+	return entry
+}
+
+// update writes a new value to the given entry and marks it as the most recently used. It must be called with the
+// lock held.
+func (c *InMemoryCache[K, V]) update(entry *entry[K, V], value V) *entry[K, V] {
+	entry.value = value
+	entry.timestamp = c.clock()
+	entry.accessed = entry.timestamp
+	entry.gen = c.nextGen()
+	entry.jitter = c.randomJitter()
+	entry.ttl = c.valueTTL(value)
+	c.cost -= entry.cost
+	if entry.protected {
+		// Rewritten entries go back to the probationary segment
+		entry.protected = false
+		c.protectedLen--
+	}
+	if entry.windowed {
+		// Rewritten entries have already been admitted
+		entry.windowed = false
+		c.windowLen--
+	}
+	entry.cost = c.weigh(entry.key, value)
+	c.cost += entry.cost
+	entry.soft = 0
+	entry.err = nil
+	c.touchFrequency(entry, entry.timestamp)
+	entry.source = ""
+	entry.penalty = 0
+	entry.priority = 0
+	c.untag(entry)
+	c.list.MoveToFront(entry)
+	entry.moved = c.nextMove()
+	return entry
+}
+
+// add inserts a new entry as the most recently used one, evicting the least recently used entry if the cache is full.
+// It must be called with the lock held.
+func (c *InMemoryCache[K, V]) add(key K, value V) *entry[K, V] {
 	if c.list.Len() >= c.capacity {
-		elem := c.list.Back()
-		entry := elem.Value.(*entry[K, V])
-		delete(c.cache, entry.key)
-		c.list.Remove(elem)
+		c.evict()
 	}
+	return c.insert(key, value)
+}
 
-	entry := &entry[K, V]{key: key, value: value, timestamp: time.Now()}
-	elem := c.list.PushFront(entry)
-	c.cache[key] = elem
+// insert inserts a new entry as the most recently used one without checking the capacity. It must be called with the
+// lock held.
+func (c *InMemoryCache[K, V]) insert(key K, value V) *entry[K, V] {
+	now := c.clock()
+	e := c.newEntry()
+	*e = entry[K, V]{
+		key:       key,
+		value:     value,
+		timestamp: now,
+		accessed:  now,
+		gen:       c.nextGen(),
+		freq:      1,
+		freqAt:    now,
+		jitter:    c.randomJitter(),
+		ttl:       c.valueTTL(value),
+		cost:      c.weigh(key, value),
+	}
+	c.cost += e.cost
+	c.cache[key] = c.list.PushFront(e)
+	e.moved = c.nextMove()
+	if c.sketch != nil {
+		e.windowed = true
+		c.windowLen++
+		c.spill()
+	}
+	return e
+}
 
-	return value, nil
+// trim evicts entries until the cache fits its capacity and its max cost. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) trim() {
+	c.drainReads()
+	for (c.list.Len() > c.capacity || c.maxCost > 0 && c.cost > c.maxCost) && c.evict() {
+	}
 }
 
-// RemoveExpired removes all expired entries from the cache.
-func (c *InMemoryCache[K, V]) RemoveExpired() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+// evict removes the entry chosen by the eviction policy to make room for a new one. It returns false if all the
+// entries are pinned. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) evict() bool {
+	victim := c.victim()
+	if victim == nil {
+		return false
+	}
+	if c.beforeEvict != nil {
+		victim = c.consent(victim)
+	}
+	c.countEviction(victim.key)
+	if c.overflow != nil {
+		c.writeOverflow(victim)
+	}
+	c.removeEntry(victim)
+	return true
+}
 
-	for elem := c.list.Back(); elem != nil; elem = elem.Prev() {
-		entry := elem.Value.(*entry[K, V])
-		if time.Since(entry.timestamp) > c.ttl {
-			delete(c.cache, entry.key)
-			c.list.Remove(elem)
-		} else {
-			break
+// victim returns the entry to evict: the least recently used unpinned one, unless miss-penalty-aware,
+// deadline-ordered, priority-aware, LFU, segmented LRU or TinyLFU eviction is enabled. It returns nil if all the
+// entries are pinned. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) victim() *entry[K, V] {
+	if c.edf {
+		return c.earliestDeadline()
+	}
+	if c.prioritized {
+		return c.lowestPriority()
+	}
+	if c.lfu {
+		return c.leastFrequent()
+	}
+	if c.protectedShare > 0 {
+		return c.probationVictim()
+	}
+	if c.sketch != nil {
+		return c.admissionVictim()
+	}
+	victim := c.evictable(c.list.Back())
+	if victim == nil || c.penaltyWindow <= 1 {
+		return victim
+	}
+	e := c.evictable(victim.Prev())
+	for i := 1; i < c.penaltyWindow && e != nil; i++ {
+		if e.penalty < victim.penalty {
+			victim = e
 		}
+		e = c.evictable(e.Prev())
+	}
+	return victim
+}
+
+// removeEntry removes the given entry and its key from the cache. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) removeEntry(entry *entry[K, V]) {
+	delete(c.cache, entry.key)
+	c.list.Remove(entry)
+	c.cost -= entry.cost
+	if entry.protected {
+		c.protectedLen--
+	}
+	if entry.windowed {
+		c.windowLen--
 	}
+	c.untag(entry)
+	c.recycle(entry)
 }
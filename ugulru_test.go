@@ -1,7 +1,10 @@
 package ugulru_test
 
 import (
+	"context"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -202,3 +205,281 @@ func TestInMemoryCache_RemoveExpired(t *testing.T) {
 	assert.True(t, ok, "key5 should not be expired")
 	assert.Equal(t, 5, value)
 }
+
+func TestInMemoryCache_Range(t *testing.T) {
+	t.Run("Test visiting all entries", func(t *testing.T) {
+		cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+		cache.Put("key1", 1)
+		cache.Put("key2", 2)
+		cache.Put("key3", 3)
+
+		var keys []string
+		var sum int
+		cache.Range(func(key string, value int) bool {
+			keys = append(keys, key)
+			sum += value
+			return true
+		})
+		assert.Equal(t, []string{"key3", "key2", "key1"}, keys) // most recently used first
+		assert.Equal(t, 6, sum)
+	})
+
+	t.Run("Test early termination", func(t *testing.T) {
+		cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+		cache.Put("key1", 1)
+		cache.Put("key2", 2)
+		cache.Put("key3", 3)
+
+		var visited int
+		cache.Range(func(key string, value int) bool {
+			visited++
+			return visited < 2
+		})
+		assert.Equal(t, 2, visited)
+	})
+
+	t.Run("Test skipping expired entries", func(t *testing.T) {
+		cache := ugulru.NewInMemoryCache[string, int](3, 100*time.Millisecond)
+		cache.Put("key1", 1)
+		time.Sleep(200 * time.Millisecond)
+		cache.Put("key2", 2)
+
+		var keys []string
+		cache.Range(func(key string, value int) bool {
+			keys = append(keys, key)
+			return true
+		})
+		assert.Equal(t, []string{"key2"}, keys)
+	})
+}
+
+func TestInMemoryCache_GetWithGeneration(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+
+	_, _, ok := cache.GetWithGeneration("key1")
+	assert.False(t, ok)
+
+	cache.Put("key1", 1)
+	value, gen1, ok := cache.GetWithGeneration("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	// Reading again doesn't change the generation
+	_, gen, _ := cache.GetWithGeneration("key1")
+	assert.Equal(t, gen1, gen)
+
+	// Writing the same value bumps the generation
+	cache.Put("key1", 1)
+	value, gen2, ok := cache.GetWithGeneration("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	assert.Greater(t, gen2, gen1)
+
+	// Generations are monotone across keys
+	cache.Put("key2", 2)
+	_, gen3, _ := cache.GetWithGeneration("key2")
+	assert.Greater(t, gen3, gen2)
+}
+
+func TestInMemoryCache_Resize(t *testing.T) {
+	t.Run("Test shrinking", func(t *testing.T) {
+		cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+		cache.Put("key1", 1)
+		cache.Put("key2", 2)
+		cache.Put("key3", 3)
+		cache.Get("key1") // key2 is now the least recently used
+
+		cache.Resize(2)
+		_, ok := cache.Get("key2")
+		assert.False(t, ok, "key2 should be evicted")
+		_, ok = cache.Get("key1")
+		assert.True(t, ok)
+		_, ok = cache.Get("key3")
+		assert.True(t, ok)
+
+		cache.Put("key4", 4)
+		_, ok = cache.Get("key1")
+		assert.False(t, ok, "key1 should be evicted by the new capacity")
+	})
+
+	t.Run("Test growing", func(t *testing.T) {
+		cache := ugulru.NewInMemoryCache[string, int](1, 5*time.Minute)
+		cache.Put("key1", 1)
+		cache.Resize(2)
+		cache.Put("key2", 2)
+		_, ok := cache.Get("key1")
+		assert.True(t, ok)
+		_, ok = cache.Get("key2")
+		assert.True(t, ok)
+	})
+
+	t.Run("Test invalid capacity", func(t *testing.T) {
+		cache := ugulru.NewInMemoryCache[string, int](1, 5*time.Minute)
+		assert.Panics(t, func() { cache.Resize(0) })
+	})
+}
+
+func TestInMemoryCache_SetTTL(t *testing.T) {
+	t.Run("Test shortening the TTL expires existing entries", func(t *testing.T) {
+		cache := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+		cache.Put("key1", 1)
+		time.Sleep(100 * time.Millisecond)
+
+		assert.Equal(t, 5*time.Minute, cache.TTL())
+		cache.SetTTL(50 * time.Millisecond)
+		assert.Equal(t, 50*time.Millisecond, cache.TTL())
+		_, ok := cache.Get("key1")
+		assert.False(t, ok, "key1 should be expired by the new TTL")
+
+		cache.Put("key2", 2)
+		value, ok := cache.Get("key2")
+		assert.True(t, ok)
+		assert.Equal(t, 2, value)
+	})
+
+	t.Run("Test extending the TTL prolongs existing entries", func(t *testing.T) {
+		cache := ugulru.NewInMemoryCache[string, int](2, 100*time.Millisecond)
+		cache.Put("key1", 1)
+
+		cache.SetTTL(5 * time.Minute)
+		time.Sleep(200 * time.Millisecond)
+		value, ok := cache.Get("key1")
+		assert.True(t, ok, "key1 should live as long as the new TTL")
+		assert.Equal(t, 1, value)
+	})
+}
+
+func TestInMemoryCache_GetWithExpiry(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+
+	_, _, ok := cache.GetWithExpiry("key1")
+	assert.False(t, ok)
+
+	before := time.Now()
+	cache.Put("key1", 1)
+	after := time.Now()
+
+	value, expiry, ok := cache.GetWithExpiry("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	assert.False(t, expiry.Before(before.Add(5*time.Minute-time.Millisecond))) // millisecond resolution
+	assert.False(t, expiry.After(after.Add(5*time.Minute)))
+}
+
+func TestInMemoryCache_Touch(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](2, 200*time.Millisecond)
+	assert.False(t, cache.Touch("key1"))
+
+	cache.Put("key1", 1)
+	time.Sleep(150 * time.Millisecond)
+	assert.True(t, cache.Touch("key1"))
+	time.Sleep(150 * time.Millisecond)
+
+	value, ok := cache.Get("key1")
+	assert.True(t, ok, "key1 should be kept alive by Touch")
+	assert.Equal(t, 1, value)
+
+	time.Sleep(250 * time.Millisecond)
+	assert.False(t, cache.Touch("key1"), "key1 should not be revived once expired")
+}
+
+func TestInMemoryCache_LoadContext(t *testing.T) {
+	t.Run("Test loading and caching", func(t *testing.T) {
+		cache := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+		type ctxKey struct{}
+		ctx := context.WithValue(context.Background(), ctxKey{}, 1)
+
+		value, err := cache.LoadContext(ctx, "key1", func(ctx context.Context) (int, error) {
+			return ctx.Value(ctxKey{}).(int), nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, value)
+
+		value, err = cache.LoadContext(ctx, "key1", func(ctx context.Context) (int, error) {
+			return 2, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, value) // should return the cached value
+	})
+
+	t.Run("Test sharing an in-flight load", func(t *testing.T) {
+		cache := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+		var calls atomic.Int32
+		loader := func(ctx context.Context) (int, error) {
+			calls.Add(1)
+			time.Sleep(50 * time.Millisecond)
+			return 1, nil
+		}
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				value, err := cache.LoadContext(context.Background(), "key1", loader)
+				assert.NoError(t, err)
+				assert.Equal(t, 1, value)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("Test waiter cancellation", func(t *testing.T) {
+		cache := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+		started := make(chan struct{})
+		go cache.LoadContext(context.Background(), "key1", func(ctx context.Context) (int, error) {
+			close(started)
+			time.Sleep(200 * time.Millisecond)
+			return 1, nil
+		})
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err := cache.LoadContext(ctx, "key1", func(ctx context.Context) (int, error) {
+			return 2, nil
+		})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 100*time.Millisecond)
+	})
+
+	t.Run("Test loader error", func(t *testing.T) {
+		cache := ugulru.NewInMemoryCache[string, int](2, 5*time.Minute)
+		_, err := cache.LoadContext(context.Background(), "key1", func(ctx context.Context) (int, error) {
+			return 0, fmt.Errorf("loader error")
+		})
+		assert.Error(t, err)
+		_, ok := cache.Get("key1")
+		assert.False(t, ok)
+	})
+}
+
+func TestInMemoryCache_GetDoesNotAllocate(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[int, int](10, 5*time.Minute)
+	for i := range 10 {
+		cache.Put(i, i)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		cache.Get(3)
+		cache.Get(42)
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+func TestInMemoryCache_ChurnDoesNotAllocate(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[int, int](10, 5*time.Minute)
+	key := 0
+	for ; key < 100; key++ {
+		cache.Put(key, key)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		cache.Put(key, key)
+		cache.Remove(key - 5)
+		key++
+	})
+	assert.Equal(t, 0.0, allocs)
+}
@@ -0,0 +1,34 @@
+package ugulru
+
+// WithOnBeforeEvict registers a hook that is called before an entry is evicted to make room for a new one and can veto
+// the eviction by returning false, e.g. while the entry is briefly in use by an in-flight operation. When the victim
+// is vetoed, the next least recently used entries are tried, up to maxRetries more; if they are all vetoed, the first
+// victim is evicted anyway, so the cache never exceeds its capacity. The hook is called with the lock held, so it must
+// not call any methods of the cache.
+func WithOnBeforeEvict[K comparable, V any](hook func(key K, value V) bool, maxRetries int) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.beforeEvict = hook
+		c.evictRetries = maxRetries
+	}
+}
+
+// consent returns the first of the eviction candidates whose eviction is not vetoed, starting with the given victim
+// and continuing with the least recently used entries. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) consent(victim *entry[K, V]) *entry[K, V] {
+	candidate, next := victim, c.evictable(c.list.Back())
+	for retries := 0; ; retries++ {
+		if c.beforeEvict(candidate.key, candidate.value) {
+			return candidate
+		}
+		if retries >= c.evictRetries {
+			return victim
+		}
+		if next == victim {
+			next = c.evictable(next.Prev())
+		}
+		if next == nil {
+			return victim
+		}
+		candidate, next = next, c.evictable(next.Prev())
+	}
+}
@@ -0,0 +1,40 @@
+package ugulru_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithOnBeforeEvict(t *testing.T) {
+	busy := map[string]bool{"key1": true}
+	var asked []string
+	hook := func(key string, value int) bool {
+		asked = append(asked, key)
+		return !busy[key]
+	}
+	cache := ugulru.NewInMemoryCache(3, 5*time.Minute, ugulru.WithOnBeforeEvict(hook, 1))
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+	cache.Put("key3", 3)
+
+	// key1 is vetoed, so the next least recently used entry is evicted
+	cache.Put("key4", 4)
+	assert.Equal(t, []string{"key1", "key2"}, asked)
+	_, ok := cache.Get("key1")
+	assert.True(t, ok)
+	_, ok = cache.Get("key2")
+	assert.False(t, ok)
+
+	// When all the tried candidates are vetoed, the first victim is evicted anyway
+	busy["key3"] = true
+	busy["key4"] = true
+	asked = nil
+	cache.Put("key5", 5) // key3 is now the least recently used entry
+	assert.Equal(t, []string{"key3", "key4"}, asked)
+	_, ok = cache.Get("key3")
+	assert.False(t, ok)
+	assert.Equal(t, 3, cache.Stats().Len)
+}
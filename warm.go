@@ -0,0 +1,41 @@
+package ugulru
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// WriteHotKeys writes up to n of the hottest keys of the cache to w as JSON, hottest first. Only the keys are written,
+// so the list is cheap to persist at shutdown and can be read back with ReadHotKeys to decide which keys to preload
+// first on the next startup.
+func (c *InMemoryCache[K, V]) WriteHotKeys(w io.Writer, n int) error {
+	hot := c.TopN(n)
+	keys := make([]K, len(hot))
+	for i, h := range hot {
+		keys[i] = h.Key
+	}
+	return json.NewEncoder(w).Encode(keys)
+}
+
+// ReadHotKeys reads a list of keys written by WriteHotKeys from r, hottest first.
+func ReadHotKeys[K comparable](r io.Reader) ([]K, error) {
+	var keys []K
+	err := json.NewDecoder(r).Decode(&keys)
+	return keys, err
+}
+
+// Warm loads the given keys into the cache in order, using loader for the keys that are not cached yet. It keeps going
+// when the loader fails and returns the joined errors of all the failed keys.
+func (c *InMemoryCache[K, V]) Warm(keys []K, loader func(key K) (V, error)) error {
+	var errs []error
+	for _, key := range keys {
+		_, err := c.Load(key, func() (V, error) {
+			return loader(key)
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,49 @@
+package ugulru_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHotKeys(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+	cache.Put("key1", 1)
+	cache.Put("key2", 2)
+	cache.Put("key3", 3)
+	cache.Get("key3")
+	cache.Get("key3")
+	cache.Get("key1")
+
+	var buf bytes.Buffer
+	assert.NoError(t, cache.WriteHotKeys(&buf, 2))
+
+	keys, err := ugulru.ReadHotKeys[string](&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"key3", "key1"}, keys)
+}
+
+func TestInMemoryCache_Warm(t *testing.T) {
+	cache := ugulru.NewInMemoryCache[string, int](3, 5*time.Minute)
+	cache.Put("key1", 10)
+
+	errLoad := errors.New("loader error")
+	err := cache.Warm([]string{"key1", "key2", "bad", "key3"}, func(key string) (int, error) {
+		if key == "bad" {
+			return 0, errLoad
+		}
+		return len(key), nil
+	})
+	assert.ErrorIs(t, err, errLoad)
+
+	value, ok := cache.Get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 10, value) // already cached
+	value, ok = cache.Get("key3")
+	assert.True(t, ok)
+	assert.Equal(t, 4, value)
+}
@@ -0,0 +1,248 @@
+package ugulru
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WriteBehindConfig configures WithWriteBehind. Zero fields take their default value.
+type WriteBehindConfig[K comparable] struct {
+	// QueueSize is the number of keys with a pending write at which writers start waiting, 1024 by default. Writes to a
+	// key that already has a pending write replace it without taking more room.
+	QueueSize int
+	// BatchSize is the maximum number of writes sent to the store at once, 100 by default.
+	BatchSize int
+	// Interval is how long pending writes wait for a batch to fill up before being sent anyway, 100ms by default.
+	Interval time.Duration
+	// Retries is the number of times a failed write is retried before it is dropped.
+	Retries int
+	// RetryDelay is the delay between retries.
+	RetryDelay time.Duration
+	// OnError is called with the error of a write that is dropped after all its retries failed.
+	OnError func(key K, err error)
+}
+
+// BatchStore is a Store that can write several values at once, such as with a multi-row insert. WithWriteBehind uses
+// SetMulti when the store implements it.
+type BatchStore[K comparable, V any] interface {
+	Store[K, V]
+	// SetMulti stores the given values.
+	SetMulti(ctx context.Context, values map[K]V) error
+}
+
+// WithWriteBehind works like WithWriteThrough but writes to the store asynchronously: Put and Write update the cache
+// immediately and queue the write, which is sent to the store later in batches, retrying failed writes as configured.
+// Remove and Delete are queued likewise. Misses read the queued value, if any, before the store. Writers block while
+// the queue is full, slowing them down to the pace of the store, but wait before taking the lock of the cache, so reads
+// go on meanwhile; the queue may exceed its size by the writes of the writers that got past the wait concurrently.
+// Flush sends the queued writes without waiting for batches to fill up, and waits for them to complete, so calling it
+// or Close on shutdown doesn't lose writes.
+func WithWriteBehind[K comparable, V any](store Store[K, V], config WriteBehindConfig[K]) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.backing = store
+		c.behind = newWriteBehind(store, config)
+	}
+}
+
+// writeBehind is the queue of writes of a cache in write-behind mode.
+type writeBehind[K comparable, V any] struct {
+	store  Store[K, V]
+	config WriteBehindConfig[K]
+
+	mu      sync.Mutex
+	space   *sync.Cond
+	pending map[K]queuedWrite[V]
+	order   []K
+	running bool
+	urgent  bool
+	kick    chan struct{}
+}
+
+// queuedWrite is a pending write of a value, or a deletion.
+type queuedWrite[V any] struct {
+	value   V
+	deleted bool
+}
+
+func newWriteBehind[K comparable, V any](store Store[K, V], config WriteBehindConfig[K]) *writeBehind[K, V] {
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1024
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.Interval <= 0 {
+		config.Interval = 100 * time.Millisecond
+	}
+	w := &writeBehind[K, V]{
+		store:   store,
+		config:  config,
+		pending: make(map[K]queuedWrite[V]),
+		kick:    make(chan struct{}, 1),
+	}
+	w.space = sync.NewCond(&w.mu)
+	return w
+}
+
+// throttle blocks while the write-behind queue is full. It must be called without the lock held, before the writes
+// it makes room for.
+func (c *InMemoryCache[K, V]) throttle() {
+	if c.behind != nil {
+		c.behind.wait()
+	}
+}
+
+// wait blocks while the queue is full.
+func (w *writeBehind[K, V]) wait() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for len(w.pending) >= w.config.QueueSize {
+		w.space.Wait()
+	}
+}
+
+// enqueue queues a write of the value for the given key, or its deletion, without waiting for room, since it is called
+// with the lock of the cache held. It starts the writer of the given cache if it isn't running.
+func (w *writeBehind[K, V]) enqueue(c *InMemoryCache[K, V], key K, value V, deleted bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.pending[key]; !ok {
+		w.order = append(w.order, key)
+	}
+	w.pending[key] = queuedWrite[V]{value: value, deleted: deleted}
+	if len(w.order) >= w.config.BatchSize {
+		w.wake()
+	}
+	if !w.running {
+		w.running = true
+		c.goBackground(w.run)
+	}
+}
+
+// lookup returns the queued write for the given key, if any.
+func (w *writeBehind[K, V]) lookup(key K) (queuedWrite[V], bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	write, ok := w.pending[key]
+	return write, ok
+}
+
+// flush makes the writer send the queued writes without waiting for batches to fill up, until the queue is empty.
+func (w *writeBehind[K, V]) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.running {
+		w.urgent = true
+		w.wake()
+	}
+}
+
+// wake wakes the writer up if it is waiting for a batch to fill up. It must be called with the lock held.
+func (w *writeBehind[K, V]) wake() {
+	select {
+	case w.kick <- struct{}{}:
+	default:
+	}
+}
+
+// run sends the queued writes to the store in batches until the queue is empty.
+func (w *writeBehind[K, V]) run() {
+	timer := time.NewTimer(w.config.Interval)
+	defer timer.Stop()
+
+	for {
+		w.mu.Lock()
+		wait := !w.urgent && len(w.order) < w.config.BatchSize
+		w.mu.Unlock()
+		if wait {
+			timer.Reset(w.config.Interval)
+			select {
+			case <-timer.C:
+			case <-w.kick:
+			}
+		}
+
+		batch, writes := w.next()
+		if len(batch) == 0 {
+			return
+		}
+		w.write(batch, writes)
+	}
+}
+
+// next removes the next batch of writes from the queue. If the queue is empty, it stops the writer.
+func (w *writeBehind[K, V]) next() ([]K, []queuedWrite[V]) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := min(len(w.order), w.config.BatchSize)
+	if n == 0 {
+		w.running = false
+		w.urgent = false
+		return nil, nil
+	}
+	keys := w.order[:n:n]
+	w.order = w.order[n:]
+	writes := make([]queuedWrite[V], n)
+	for i, key := range keys {
+		writes[i] = w.pending[key]
+		delete(w.pending, key)
+	}
+	w.space.Broadcast()
+	return keys, writes
+}
+
+// write sends the given batch of writes to the store.
+func (w *writeBehind[K, V]) write(keys []K, writes []queuedWrite[V]) {
+	ctx := context.Background()
+	batch, ok := w.store.(BatchStore[K, V])
+	values := make(map[K]V)
+	for i, key := range keys {
+		switch {
+		case writes[i].deleted:
+			w.report(key, w.attempt(func() error {
+				return w.store.Delete(ctx, key)
+			}))
+		case ok:
+			values[key] = writes[i].value
+		default:
+			w.report(key, w.attempt(func() error {
+				return w.store.Set(ctx, key, writes[i].value)
+			}))
+		}
+	}
+	if len(values) == 0 {
+		return
+	}
+	err := w.attempt(func() error {
+		return batch.SetMulti(ctx, values)
+	})
+	for key := range values {
+		w.report(key, err)
+	}
+}
+
+// attempt calls fn until it succeeds or the retries are exhausted, and returns the last error.
+func (w *writeBehind[K, V]) attempt(fn func() error) error {
+	err := fn()
+	for range w.config.Retries {
+		if err == nil {
+			break
+		}
+		time.Sleep(w.config.RetryDelay)
+		err = fn()
+	}
+	return err
+}
+
+// report reports the error of a dropped write for the given key, if any.
+func (w *writeBehind[K, V]) report(key K, err error) {
+	if err != nil && w.config.OnError != nil {
+		w.config.OnError(key, err)
+	}
+}
@@ -0,0 +1,165 @@
+package ugulru_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchStore is a BatchStore recording the batches it receives, for tests.
+type batchStore[K comparable, V any] struct {
+	*mapStore[K, V]
+	batches []int
+}
+
+func (s *batchStore[K, V]) SetMulti(ctx context.Context, values map[K]V) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.batches = append(s.batches, len(values))
+	for key, value := range values {
+		s.data[key] = value
+	}
+	return nil
+}
+
+// stalledStore is a Store whose writes block until it is released, for tests.
+type stalledStore[K comparable, V any] struct {
+	*mapStore[K, V]
+	release chan struct{}
+}
+
+func (s *stalledStore[K, V]) Set(ctx context.Context, key K, value V) error {
+	<-s.release
+	return s.mapStore.Set(ctx, key, value)
+}
+
+func TestWithWriteBehind(t *testing.T) {
+	store := &batchStore[int, int]{mapStore: newMapStore[int, int]()}
+	cache := ugulru.NewInMemoryCache(100, time.Minute, ugulru.WithWriteBehind[int, int](store, ugulru.WriteBehindConfig[int]{
+		BatchSize: 10,
+		Interval:  time.Hour,
+	}))
+
+	for i := range 25 {
+		cache.Put(i, i)
+	}
+	cache.Put(0, 100)
+	value, ok := cache.Get(24)
+	assert.True(t, ok, "the cache should be updated immediately")
+	assert.Equal(t, 24, value)
+
+	cache.Remove(1)
+	require.NoError(t, cache.Flush(context.Background()))
+	store.mu.Lock()
+	assert.Len(t, store.data, 24)
+	assert.Equal(t, 100, store.data[0])
+	assert.NotContains(t, store.data, 1)
+	assert.Less(t, len(store.batches), 5, "writes should be batched")
+	for _, size := range store.batches {
+		assert.LessOrEqual(t, size, 10)
+	}
+	store.mu.Unlock()
+}
+
+func TestWithWriteBehind_ReadQueued(t *testing.T) {
+	store := newMapStore[string, int]()
+	cache := ugulru.NewInMemoryCache(1, time.Minute, ugulru.WithWriteBehind[string, int](store, ugulru.WriteBehindConfig[string]{
+		Interval: time.Hour,
+	}))
+
+	cache.Put("key1", 1)
+	cache.Put("key2", 2) // evicts key1 before it is written to the store
+	value, err := cache.Load("key1", func() (int, error) {
+		return 0, errors.New("loader should not be called")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, value)
+
+	require.NoError(t, cache.Flush(context.Background()))
+	assert.Equal(t, map[string]int{"key1": 1, "key2": 2}, store.data)
+}
+
+func TestWithWriteBehind_Retry(t *testing.T) {
+	store := newMapStore[string, int]()
+	store.err = errors.New("database is down")
+	var mu sync.Mutex
+	var failed []string
+	cache := ugulru.NewInMemoryCache(10, time.Minute, ugulru.WithWriteBehind[string, int](store, ugulru.WriteBehindConfig[string]{
+		Interval:   time.Millisecond,
+		Retries:    2,
+		RetryDelay: time.Millisecond,
+		OnError: func(key string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			failed = append(failed, key)
+		},
+	}))
+
+	cache.Put("key1", 1)
+	require.NoError(t, cache.Flush(context.Background()))
+	assert.Equal(t, []string{"key1"}, failed)
+
+	store.mu.Lock()
+	store.err = nil
+	store.mu.Unlock()
+	cache.Put("key2", 2)
+	require.NoError(t, cache.Flush(context.Background()))
+	assert.Equal(t, map[string]int{"key2": 2}, store.data)
+	assert.Equal(t, []string{"key1"}, failed)
+}
+
+func TestWithWriteBehind_QueueFull(t *testing.T) {
+	store := newMapStore[int, int]()
+	cache := ugulru.NewInMemoryCache(100, time.Minute, ugulru.WithWriteBehind[int, int](store, ugulru.WriteBehindConfig[int]{
+		QueueSize: 2,
+		BatchSize: 1,
+		Interval:  time.Millisecond,
+	}))
+
+	// Writers block until there is room in the queue rather than losing writes
+	for i := range 20 {
+		cache.Put(i, i)
+	}
+	require.NoError(t, cache.Flush(context.Background()))
+	assert.Len(t, store.data, 20)
+}
+
+func TestWithWriteBehind_QueueFullReads(t *testing.T) {
+	store := &stalledStore[int, int]{mapStore: newMapStore[int, int](), release: make(chan struct{})}
+	cache := ugulru.NewInMemoryCache(100, time.Minute, ugulru.WithWriteBehind[int, int](store, ugulru.WriteBehindConfig[int]{
+		QueueSize: 1,
+		BatchSize: 1,
+		Interval:  time.Millisecond,
+	}))
+	cache.Put(1, 1)
+	cache.Put(2, 2)
+
+	// A writer waiting for room in the queue doesn't hold the lock of the cache
+	done := make(chan struct{})
+	go func() {
+		cache.Put(3, 3)
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	value, ok := cache.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	select {
+	case <-done:
+		t.Fatal("the writer should wait for room in the queue")
+	default:
+	}
+
+	close(store.release)
+	<-done
+	require.NoError(t, cache.Flush(context.Background()))
+	assert.Len(t, store.data, 3)
+}
@@ -0,0 +1,121 @@
+package ugulru
+
+import (
+	"context"
+	"errors"
+)
+
+// WithWriteThrough makes the cache the single access path to the given store, such as a database. Every method that
+// writes to the cache, such as Put, PutMulti, Swap or Update, writes the value to the store before the cache, and every
+// method that removes entries, such as Remove, Pop, RemoveIf or InvalidateTag, deletes them from the store before the
+// cache, with the lock of the cache held, so the cache and the store are updated in the same order. If the store fails
+// to write a value, the key is removed from the cache instead; only Write and Delete return the error of the store. On
+// a miss, Load and its variants read the value from the store before calling the loader, which is only called if the
+// store returns ErrNotFound.
+func WithWriteThrough[K comparable, V any](store Store[K, V]) Option[K, V] {
+	return func(c *InMemoryCache[K, V]) {
+		c.backing = store
+	}
+}
+
+// Write works like Put but returns the error of the store set by WithWriteThrough. If the store fails, the key is
+// removed from the cache instead, so the cache never holds a value the store doesn't.
+func (c *InMemoryCache[K, V]) Write(ctx context.Context, key K, value V) error {
+	c.throttle()
+	defer c.unlock(c.lock())
+
+	_, err := c.writeThrough(ctx, key, value, nil)
+	return err
+}
+
+// Delete works like Remove but returns the error of the store set by WithWriteThrough. The key is removed from the
+// cache even if the store fails, so the next read goes to the store.
+func (c *InMemoryCache[K, V]) Delete(ctx context.Context, key K) error {
+	c.throttle()
+	defer c.unlock(c.lock())
+
+	return c.deleteThrough(ctx, key)
+}
+
+// discard removes the entry with the given key from the cache but not from the store, e.g. to apply an invalidation
+// made by another replica sharing the store.
+func (c *InMemoryCache[K, V]) discard(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.remove(key)
+}
+
+// discardAll removes all the entries from the cache but not from the store, and returns the number of removed entries.
+func (c *InMemoryCache[K, V]) discardAll() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.removeIf(func(K, V) bool {
+		return true
+	}, c.remove)
+}
+
+// writeThrough writes the value for the given key to the store, if any, and then to the cache, setting the metadata
+// of the entry with apply like store. It returns the written entry, or nil if the write was buffered or the store
+// failed. It must be called with the lock held.
+func (c *InMemoryCache[K, V]) writeThrough(ctx context.Context, key K, value V, apply func(*entry[K, V])) (*entry[K, V], error) {
+	if err := c.writeBacking(ctx, key, value); err != nil {
+		c.remove(key)
+		return nil, err
+	}
+	return c.store(key, value, apply), nil
+}
+
+// deleteThrough deletes the given key from the store, if any, and then from the cache. It must be called with the lock
+// held.
+func (c *InMemoryCache[K, V]) deleteThrough(ctx context.Context, key K) error {
+	err := c.deleteBacking(ctx, key)
+	c.remove(key)
+	return err
+}
+
+// writeBacking writes the value for the given key to the store, if any, or queues the write in write-behind mode. It
+// must be called with the lock held.
+func (c *InMemoryCache[K, V]) writeBacking(ctx context.Context, key K, value V) error {
+	switch {
+	case c.behind != nil:
+		c.behind.enqueue(c, key, value, false)
+	case c.backing != nil:
+		return c.backing.Set(ctx, key, value)
+	}
+	return nil
+}
+
+// deleteBacking deletes the given key from the store, if any, or queues the deletion in write-behind mode. It must be
+// called with the lock held.
+func (c *InMemoryCache[K, V]) deleteBacking(ctx context.Context, key K) error {
+	switch {
+	case c.behind != nil:
+		var zero V
+		c.behind.enqueue(c, key, zero, true)
+	case c.backing != nil:
+		return c.backing.Delete(ctx, key)
+	}
+	return nil
+}
+
+// readThrough returns a loader that reads the value for the given key from the write-behind queue or the store, and
+// falls back to the given loader if neither has it.
+func (c *InMemoryCache[K, V]) readThrough(key K, loader func(ctx context.Context) (V, error)) func(ctx context.Context) (V, error) {
+	return func(ctx context.Context) (V, error) {
+		if c.behind != nil {
+			if write, ok := c.behind.lookup(key); ok {
+				if write.deleted {
+					return loader(ctx)
+				}
+				return write.value, nil
+			}
+		}
+		value, err := c.backing.Get(ctx, key)
+		if errors.Is(err, ErrNotFound) {
+			return loader(ctx)
+		}
+		return value, err
+	}
+}
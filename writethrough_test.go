@@ -0,0 +1,113 @@
+package ugulru_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/machine23/ugulru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithWriteThrough(t *testing.T) {
+	store := newMapStore[string, int]()
+	cache := ugulru.NewInMemoryCache(3, time.Minute, ugulru.WithWriteThrough[string, int](store))
+
+	cache.Put("key1", 1)
+	assert.Equal(t, map[string]int{"key1": 1}, store.data)
+
+	// Misses read through the store before calling the loader
+	store.data["key2"] = 2
+	value, err := cache.Load("key2", func() (int, error) {
+		return 0, errors.New("loader should not be called")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, value)
+
+	value, err = cache.LoadContext(context.Background(), "key3", func(ctx context.Context) (int, error) {
+		return 3, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, value)
+
+	cache.Remove("key1")
+	_, ok := cache.Get("key1")
+	assert.False(t, ok)
+	assert.NotContains(t, store.data, "key1")
+}
+
+func TestInMemoryCache_Write(t *testing.T) {
+	ctx := context.Background()
+	store := newMapStore[string, int]()
+	cache := ugulru.NewInMemoryCache(3, time.Minute, ugulru.WithWriteThrough[string, int](store))
+
+	require.NoError(t, cache.Write(ctx, "key1", 1))
+	value, ok := cache.Get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	// The cache never holds a value the store doesn't
+	store.err = errors.New("database is down")
+	assert.ErrorIs(t, cache.Write(ctx, "key1", 2), store.err)
+	_, ok = cache.Get("key1")
+	assert.False(t, ok)
+	assert.Equal(t, 1, store.data["key1"])
+
+	cache.Put("key2", 2)
+	_, ok = cache.Get("key2")
+	assert.False(t, ok)
+
+	assert.ErrorIs(t, cache.Delete(ctx, "key1"), store.err)
+	store.err = nil
+	require.NoError(t, cache.Delete(ctx, "key1"))
+	assert.Empty(t, store.data)
+
+	// Without a store, Write and Delete only update the cache
+	plain := ugulru.NewInMemoryCache[string, int](3, time.Minute)
+	require.NoError(t, plain.Write(ctx, "key1", 1))
+	_, ok = plain.Get("key1")
+	assert.True(t, ok)
+	require.NoError(t, plain.Delete(ctx, "key1"))
+	_, ok = plain.Get("key1")
+	assert.False(t, ok)
+}
+
+func TestWithWriteThrough_AllWrites(t *testing.T) {
+	store := newMapStore[string, int]()
+	cache := ugulru.NewInMemoryCache(10, time.Minute, ugulru.WithWriteThrough[string, int](store))
+
+	cache.PutMulti(map[string]int{"a": 1, "b": 2})
+	cache.PutWithTTL("c", 3, 0, time.Minute)
+	cache.PutTagged("d", 4, "tag")
+	cache.PutWithDeadline("e", 5, time.Now().Add(time.Minute))
+	cache.PutWithPriority("f", 6, 1)
+	cache.GetOrPut("g", 7)
+	assert.Equal(t, map[string]int{"a": 1, "b": 2, "c": 3, "d": 4, "e": 5, "f": 6, "g": 7}, store.data)
+
+	cache.Swap("a", 10)
+	cache.Update("b", func(old int, _ bool) (int, bool) {
+		return old * 10, true
+	})
+	cache.CompareAndSwap("c", 3, 30)
+	assert.Equal(t, map[string]int{"a": 10, "b": 20, "c": 30, "d": 4, "e": 5, "f": 6, "g": 7}, store.data)
+
+	cache.Pop("a")
+	cache.Update("b", func(int, bool) (int, bool) {
+		return 0, false
+	})
+	cache.CompareAndDelete("c", 30)
+	cache.RemoveMany([]string{"e"})
+	cache.InvalidateTag("tag")
+	cache.RemoveIf(func(key string, _ int) bool {
+		return key == "f"
+	})
+	assert.Equal(t, map[string]int{"g": 7}, store.data)
+
+	// A write the store rejects is not cached
+	store.err = errors.New("store down")
+	cache.PutMulti(map[string]int{"h": 8})
+	_, ok := cache.Get("h")
+	assert.False(t, ok)
+}